@@ -5,17 +5,30 @@ import (
 	"fmt"
 	"log"
 	"math/big"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 const rpcURL = "https://eth-sepolia.g.alchemy.com/v2/xxx"
 
 // 运行后会连接节点、拿到最新区块头，再把该高度的完整区块取出来，最后打印交易数量等关键信息。
+//
+// 区间扫描模式：go run . range <from> <to>
+// 可选环境变量：SCAN_WORKERS（并发数，默认 8）、SCAN_RETRIES（单块重试次数，默认 3）
 
 func main() {
+	if len(os.Args) >= 2 && os.Args[1] == "range" {
+		runRange(os.Args[2:])
+		return
+	}
+
 	// 给所有 RPC 调用一个统一超时，避免网络卡住
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
@@ -76,3 +89,58 @@ func main() {
 
 	fmt.Println("✅ Done.")
 }
+
+// runRange 并发扫描 [from, to] 区间，按高度顺序逐行打印。
+func runRange(args []string) {
+	if len(args) < 2 {
+		log.Fatalf("usage: go run . range <from> <to>")
+	}
+	from, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		log.Fatalf("invalid from: %s", args[0])
+	}
+	to, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		log.Fatalf("invalid to: %s", args[1])
+	}
+	workers := getenvInt("SCAN_WORKERS", 8)
+	retries := getenvInt("SCAN_RETRIES", 3)
+
+	// 区间可能很大，不设整体超时；Ctrl+C 取消
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cli, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		log.Fatalf("dial rpc: %v", err)
+	}
+	defer cli.Close()
+	fmt.Printf("✅ Connected to: %s  range=[%d, %d] workers=%d retries=%d\n", rpcURL, from, to, workers, retries)
+
+	start := time.Now()
+	var count, txs int
+	scanner := newRangeScanner(cli, workers, retries)
+	err = scanner.Scan(ctx, from, to, func(b *types.Block) error {
+		count++
+		txs += len(b.Transactions())
+		fmt.Printf("📦 #%d hash=%s time=%v gasUsed=%d txs=%d\n",
+			b.NumberU64(), b.Hash(), time.Unix(int64(b.Time()), 0), b.GasUsed(), len(b.Transactions()))
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("scan range: %v", err)
+	}
+	fmt.Printf("✅ Done. blocks=%d txs=%d elapsed=%v\n", count, txs, time.Since(start).Round(time.Millisecond))
+}
+
+func getenvInt(k string, def int) int {
+	v := os.Getenv(k)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Fatalf("invalid %s: %s", k, v)
+	}
+	return n
+}
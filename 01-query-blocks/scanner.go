@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// blockFetcher 是扫描器唯一依赖的能力：按高度取整块。
+// *ethclient.Client 和 simulated 后端的 Client 都满足这个接口，方便脱离真实节点扫几百个块。
+type blockFetcher interface {
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+}
+
+// rangeScanner 用有界 worker 池并发拉取 [from, to] 的区块，并严格按高度顺序回调输出。
+type rangeScanner struct {
+	client  blockFetcher
+	workers int           // 并发 worker 数
+	retries int           // 单个区块的最大重试次数（不含第一次）
+	backoff time.Duration // 首次重试等待，之后指数翻倍
+}
+
+func newRangeScanner(c blockFetcher, workers, retries int) *rangeScanner {
+	if workers < 1 {
+		workers = 1
+	}
+	if retries < 0 {
+		retries = 0
+	}
+	return &rangeScanner{client: c, workers: workers, retries: retries, backoff: 500 * time.Millisecond}
+}
+
+type scanResult struct {
+	number uint64
+	block  *types.Block
+	err    error
+}
+
+// Scan 拉取 [from, to] 的所有区块，emit 按高度从小到大依次被调用。
+// worker 完成顺序是乱的，这里用“下一个期望高度 + 暂存 map”重新排队；
+// 同时用一个窗口信号量限制已派发但尚未输出的区块数量，避免某个慢块导致内存无限堆积。
+// 任意区块重试耗尽或 emit 返回错误时，立即停止全部 worker 并返回该错误。
+func (s *rangeScanner) Scan(ctx context.Context, from, to uint64, emit func(*types.Block) error) error {
+	if from > to {
+		return fmt.Errorf("invalid range: from(%d) > to(%d)", from, to)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	// 出错提前返回时先取消，再等在途 worker 退出，保证 Scan 返回后不再有请求发出
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	window := make(chan struct{}, s.workers*4) // 在途区块上限
+	jobs := make(chan uint64)
+	results := make(chan scanResult, s.workers)
+
+	// 1) 派发：按高度顺序投递任务，窗口满了就等输出端腾位置
+	go func() {
+		defer close(jobs)
+		for n := from; ; n++ {
+			select {
+			case window <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- n:
+			case <-ctx.Done():
+				return
+			}
+			if n == to { // 放在末尾判断，避免 to == MaxUint64 时溢出死循环
+				return
+			}
+		}
+	}()
+
+	// 2) worker：取任务 → 带重试地拉块 → 回传结果
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range jobs {
+				b, err := s.fetch(ctx, n)
+				select {
+				case results <- scanResult{number: n, block: b, err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// 3) 收集：乱序到达的结果先暂存，凑齐 next 才输出
+	pending := make(map[uint64]*types.Block)
+	next := from
+	for r := range results {
+		if r.err != nil {
+			return fmt.Errorf("block %d: %w", r.number, r.err)
+		}
+		pending[r.number] = r.block
+		for {
+			b, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			if err := emit(b); err != nil {
+				return err
+			}
+			<-window
+			if next == to {
+				return nil
+			}
+			next++
+		}
+	}
+	// results 被提前关闭只可能是 ctx 取消
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("scan stopped at block %d", next)
+}
+
+// fetch 拉取单个区块；NotFound 与 ctx 取消不重试，其余错误视为网络抖动，指数退避重试。
+func (s *rangeScanner) fetch(ctx context.Context, n uint64) (*types.Block, error) {
+	num := new(big.Int).SetUint64(n)
+	wait := s.backoff
+	for attempt := 0; ; attempt++ {
+		b, err := s.client.BlockByNumber(ctx, num)
+		if err == nil {
+			return b, nil
+		}
+		if errors.Is(err, ethereum.NotFound) || ctx.Err() != nil || attempt >= s.retries {
+			return nil, err
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		wait *= 2
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+)
+
+const simBlocks = 300
+
+func newSimChain(t *testing.T) *simulated.Backend {
+	t.Helper()
+	sim := simulated.NewBackend(types.GenesisAlloc{})
+	t.Cleanup(func() { sim.Close() })
+	for i := 0; i < simBlocks; i++ {
+		sim.Commit()
+	}
+	return sim
+}
+
+// probe 包在真实 fetcher 外面：统计并发数、已取回未输出的区块数，并可对指定高度注入错误或延迟
+type probe struct {
+	blockFetcher
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+	fetched     int
+	emitted     int
+	maxBuffered int
+	slow        map[uint64]time.Duration
+	failures    map[uint64]int // 剩余需要失败的次数；<0 表示一直失败
+	attempts    map[uint64]int
+}
+
+var errFlaky = errors.New("connection reset by peer")
+
+func (p *probe) BlockByNumber(ctx context.Context, n *big.Int) (*types.Block, error) {
+	num := n.Uint64()
+	p.mu.Lock()
+	p.inFlight++
+	p.maxInFlight = max(p.maxInFlight, p.inFlight)
+	p.attempts[num]++
+	fail := p.failures[num]
+	if fail > 0 {
+		p.failures[num]--
+	}
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.inFlight--
+		p.mu.Unlock()
+	}()
+
+	if d := p.slow[num]; d > 0 {
+		time.Sleep(d)
+	}
+	if fail != 0 {
+		return nil, errFlaky
+	}
+	b, err := p.blockFetcher.BlockByNumber(ctx, n)
+	if err == nil {
+		p.mu.Lock()
+		p.fetched++
+		p.maxBuffered = max(p.maxBuffered, p.fetched-p.emitted)
+		p.mu.Unlock()
+	}
+	return b, err
+}
+
+// attemptsOf 返回 num 被请求的次数
+func (p *probe) attemptsOf(num uint64) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.attempts[num]
+}
+
+// peaks 返回最大并发数与最大“已取回未输出”数
+func (p *probe) peaks() (inFlight, buffered int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.maxInFlight, p.maxBuffered
+}
+
+func (p *probe) emit(out *[]uint64) func(*types.Block) error {
+	return func(b *types.Block) error {
+		p.mu.Lock()
+		p.emitted++
+		p.mu.Unlock()
+		*out = append(*out, b.NumberU64())
+		return nil
+	}
+}
+
+func newProbe(c blockFetcher) *probe {
+	return &probe{blockFetcher: c, slow: map[uint64]time.Duration{}, failures: map[uint64]int{}, attempts: map[uint64]int{}}
+}
+
+func TestScanOrderedAndBounded(t *testing.T) {
+	sim := newSimChain(t)
+	p := newProbe(sim.Client())
+	p.slow[20] = 100 * time.Millisecond // 一个慢块：后面的块先到，必须暂存等它
+
+	const workers = 4
+	s := newRangeScanner(p, workers, 2)
+	var got []uint64
+	if err := s.Scan(context.Background(), 1, simBlocks, p.emit(&got)); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != simBlocks {
+		t.Fatalf("emitted %d blocks, want %d", len(got), simBlocks)
+	}
+	for i, n := range got {
+		if n != uint64(i+1) {
+			t.Fatalf("emit #%d = block %d, want %d", i, n, i+1)
+		}
+	}
+	inFlight, buffered := p.peaks()
+	if inFlight > workers {
+		t.Errorf("max concurrent fetches = %d, want <= %d", inFlight, workers)
+	}
+	// 慢块期间后续区块只能取到窗口上限为止
+	if buffered > workers*4 {
+		t.Errorf("max fetched-but-not-emitted = %d, want <= %d", buffered, workers*4)
+	}
+}
+
+func TestScanRetry(t *testing.T) {
+	sim := newSimChain(t)
+
+	// 失败次数不超过重试次数：最终成功
+	p := newProbe(sim.Client())
+	p.failures[50] = 2
+	s := newRangeScanner(p, 3, 2)
+	s.backoff = time.Millisecond
+	var got []uint64
+	if err := s.Scan(context.Background(), 40, 60, p.emit(&got)); err != nil {
+		t.Fatalf("scan with transient errors: %v", err)
+	}
+	if len(got) != 21 || p.attemptsOf(50) != 3 {
+		t.Fatalf("emitted %d, block 50 attempts = %d", len(got), p.attemptsOf(50))
+	}
+
+	// 一直失败：重试耗尽后返回带区块号的错误，且不再输出该块之后的区块
+	p = newProbe(sim.Client())
+	p.failures[50] = -1
+	s = newRangeScanner(p, 3, 2)
+	s.backoff = time.Millisecond
+	got = nil
+	err := s.Scan(context.Background(), 40, 60, p.emit(&got))
+	if !errors.Is(err, errFlaky) || !strings.Contains(err.Error(), "block 50") {
+		t.Fatalf("err = %v, want block 50: %v", err, errFlaky)
+	}
+	if p.attemptsOf(50) != 3 {
+		t.Errorf("block 50 attempts = %d, want 3", p.attemptsOf(50))
+	}
+	for _, n := range got {
+		if n >= 50 {
+			t.Fatalf("emitted block %d after failed block 50", n)
+		}
+	}
+
+	// NotFound（超出链头）不重试
+	p = newProbe(sim.Client())
+	s = newRangeScanner(p, 2, 3)
+	s.backoff = time.Second
+	err = s.Scan(context.Background(), simBlocks, simBlocks+1, p.emit(new([]uint64)))
+	if !errors.Is(err, ethereum.NotFound) {
+		t.Fatalf("err = %v, want NotFound", err)
+	}
+	if p.attemptsOf(simBlocks+1) != 1 {
+		t.Errorf("NotFound attempts = %d, want 1", p.attemptsOf(simBlocks+1))
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"example.com/ethclient-demo/pkg/units"
+)
+
+// runExplorer 区块浏览器模式：一次拉全块交易 + 全部收据，打印每笔交易一行的表格。
+//
+//	go run ./02-query-tx block [number]     不给高度时取最新块
+//
+// 收据优先用一次 eth_getBlockReceipts；节点不支持时退回批量 eth_getTransactionReceipt（每批 BATCH 个，默认 100）。
+// 发送方在本地按 LatestSignerForChainID 并发恢复，不额外请求节点。环境变量 RPC_URL 可覆盖默认节点。
+func runExplorer(args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	url := os.Getenv("RPC_URL")
+	if url == "" {
+		url = rpcURL
+	}
+	cli, err := ethclient.DialContext(ctx, url)
+	must(err, "dial rpc")
+	defer cli.Close()
+
+	var number *big.Int // nil = latest
+	if len(args) > 0 {
+		n, err := strconv.ParseUint(args[0], 10, 64)
+		must(err, "parse block number")
+		number = new(big.Int).SetUint64(n)
+	}
+	batch := 100
+	if v := os.Getenv("BATCH"); v != "" {
+		batch, err = strconv.Atoi(v)
+		if err != nil || batch < 1 {
+			log.Fatalf("❌ invalid BATCH: %s", v)
+		}
+	}
+
+	chainID, err := cli.ChainID(ctx)
+	must(err, "chain id")
+	start := time.Now()
+	blk, err := cli.BlockByNumber(ctx, number)
+	must(err, "block by number")
+	txs := blk.Transactions()
+
+	receipts, how, err := fetchReceipts(ctx, cli, blk, batch)
+	must(err, "fetch receipts")
+	senders := recoverSenders(txs, types.LatestSignerForChainID(chainID))
+	elapsed := time.Since(start)
+
+	fmt.Printf("📦 Block #%v  hash=%s  time=%v  txs=%d  gasUsed=%d/%d  baseFee=%s wei\n",
+		blk.Number(), blk.Hash().Hex(), time.Unix(int64(blk.Time()), 0), len(txs), blk.GasUsed(), blk.GasLimit(), blk.BaseFee())
+	fmt.Printf("⏱  fetched in %s (receipts via %s)\n\n", elapsed.Round(time.Millisecond), how)
+
+	fmt.Printf("%4s  %-13s  %-13s  %-13s  %4s  %-7s  %9s  %12s  %14s\n",
+		"#", "hash", "from", "to", "type", "status", "gasUsed", "price(gwei)", "fee(ETH)")
+	totalFee := new(big.Int)
+	failed := 0
+	for i, tx := range txs {
+		rcp := receipts[i]
+		from := "<recover failed>"
+		if senders[i] != (common.Address{}) {
+			from = abbr(senders[i].Hex())
+		}
+		to := "<create>"
+		if tx.To() != nil {
+			to = abbr(tx.To().Hex())
+		}
+		status := "SUCCESS"
+		if rcp.Status != types.ReceiptStatusSuccessful {
+			status = "FAIL"
+			failed++
+		}
+		fee := receiptFee(rcp)
+		totalFee.Add(totalFee, fee)
+		fmt.Printf("%4d  %-13s  %-13s  %-13s  %4d  %-7s  %9d  %12s  %14s\n",
+			i, abbr(tx.Hash().Hex()), from, to, tx.Type(), status, rcp.GasUsed,
+			units.FormatFixed(rcp.EffectiveGasPrice, units.Gwei, 4), units.FormatFixed(fee, units.Ether, 8))
+	}
+
+	fmt.Printf("\n🧾 txs=%d  failed=%d  totalFee=%s wei (%s ETH)", len(txs), failed, totalFee, units.FormatEther(totalFee))
+	if bf := blk.BaseFee(); bf != nil {
+		burnt := new(big.Int).Mul(bf, new(big.Int).SetUint64(blk.GasUsed()))
+		fmt.Printf("  burnt=%s wei (%s ETH)", burnt, units.FormatEther(burnt))
+	}
+	fmt.Println()
+}
+
+// fetchReceipts 按交易顺序返回全块收据，并说明用了哪种方式
+func fetchReceipts(ctx context.Context, cli *ethclient.Client, blk *types.Block, batch int) ([]*types.Receipt, string, error) {
+	txs := blk.Transactions()
+	receipts, err := cli.BlockReceipts(ctx, rpc.BlockNumberOrHashWithHash(blk.Hash(), false))
+	if err == nil && len(receipts) == len(txs) {
+		return receipts, "eth_getBlockReceipts", checkReceipts(txs, receipts)
+	}
+	if err != nil {
+		log.Printf("⚠️  eth_getBlockReceipts unavailable (%v), falling back to batched requests", err)
+	}
+
+	receipts = make([]*types.Receipt, len(txs))
+	for lo := 0; lo < len(txs); lo += batch {
+		hi := min(lo+batch, len(txs))
+		elems := make([]rpc.BatchElem, hi-lo)
+		for i := range elems {
+			receipts[lo+i] = new(types.Receipt)
+			elems[i] = rpc.BatchElem{
+				Method: "eth_getTransactionReceipt",
+				Args:   []any{txs[lo+i].Hash()},
+				Result: receipts[lo+i],
+			}
+		}
+		if err := cli.Client().BatchCallContext(ctx, elems); err != nil {
+			return nil, "", err
+		}
+		for i, el := range elems {
+			if el.Error != nil {
+				return nil, "", fmt.Errorf("receipt %s: %w", txs[lo+i].Hash().Hex(), el.Error)
+			}
+		}
+	}
+	return receipts, fmt.Sprintf("batched eth_getTransactionReceipt x%d", (len(txs)+batch-1)/batch), checkReceipts(txs, receipts)
+}
+
+// checkReceipts 确认收据与交易一一对应（空结果会被解成零值收据，这里也能发现）
+func checkReceipts(txs types.Transactions, receipts []*types.Receipt) error {
+	for i, tx := range txs {
+		if receipts[i] == nil || receipts[i].TxHash != tx.Hash() {
+			return fmt.Errorf("receipt #%d does not match tx %s", i, tx.Hash().Hex())
+		}
+	}
+	return nil
+}
+
+// recoverSenders 用 CPU 核数个 goroutine 并发做 ecrecover；失败的位置留零地址
+func recoverSenders(txs types.Transactions, signer types.Signer) []common.Address {
+	out := make([]common.Address, len(txs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				from, err := types.Sender(signer, txs[i])
+				if err != nil {
+					log.Printf("⚠️  recover sender of %s: %v", txs[i].Hash().Hex(), err)
+					continue
+				}
+				out[i] = from
+			}
+		}()
+	}
+	for i := range txs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return out
+}
+
+// receiptFee = effectiveGasPrice * gasUsed，blob 交易再加上 blobGasPrice * blobGasUsed
+func receiptFee(rcp *types.Receipt) *big.Int {
+	fee := new(big.Int)
+	if rcp.EffectiveGasPrice != nil {
+		fee.Mul(rcp.EffectiveGasPrice, new(big.Int).SetUint64(rcp.GasUsed))
+	}
+	if rcp.BlobGasPrice != nil {
+		fee.Add(fee, new(big.Int).Mul(rcp.BlobGasPrice, new(big.Int).SetUint64(rcp.BlobGasUsed)))
+	}
+	return fee
+}
+
+// abbr 缩写哈希 / 地址为 0x1234...abcd（纯 ASCII，表格列宽才能对齐）
+func abbr(s string) string {
+	if len(s) <= 13 {
+		return s
+	}
+	return s[:6] + "..." + s[len(s)-4:]
+}
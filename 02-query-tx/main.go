@@ -4,19 +4,30 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"math"
 	"math/big"
+	"os"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+
+	"example.com/ethclient-demo/pkg/units"
 )
 
 // 建议：把你的 Alchemy/Infura/QuickNode 的 RPC URL 放到环境变量里更安全
 const rpcURL = "https://eth-sepolia.g.alchemy.com/v2/xxx"
 
 func main() {
+	// 子命令：block [number] 区块浏览器模式（批量拉收据、表格输出）；不带参数时保持原来的单笔演示
+	if len(os.Args) > 1 {
+		if os.Args[1] != "block" {
+			log.Fatalf("unknown command %q (want block)", os.Args[1])
+		}
+		runExplorer(os.Args[2:])
+		return
+	}
+
 	// 统一给所有 RPC 调用一个超时（生产中建议配合重试）
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
@@ -69,10 +80,10 @@ func main() {
 		// EffectiveGasPrice：交易打包时实际支付的每 gas 单价（EIP-1559/Legacy 都有值）
 		if rcp.EffectiveGasPrice != nil {
 			totalFee := new(big.Int).Mul(rcp.EffectiveGasPrice, big.NewInt(int64(rcp.GasUsed)))
-			fmt.Printf("  effectiveGasPrice: %s wei (≈ %f ETH)\n",
-				rcp.EffectiveGasPrice.String(), weiToEth(rcp.EffectiveGasPrice))
-			fmt.Printf("  totalFee:          %s wei (≈ %f ETH)\n",
-				totalFee.String(), weiToEth(totalFee))
+			fmt.Printf("  effectiveGasPrice: %s wei (%s Gwei)\n",
+				rcp.EffectiveGasPrice.String(), units.FormatGwei(rcp.EffectiveGasPrice))
+			fmt.Printf("  totalFee:          %s wei (%s ETH)\n",
+				totalFee.String(), units.FormatEther(totalFee))
 		}
 
 		// 只示范一笔，演示明白即可；去掉 break 可遍历所有
@@ -114,7 +125,7 @@ func printTxBasic(tx *types.Transaction) {
 	fmt.Println("tx  hash:    ", tx.Hash().Hex())
 	fmt.Println("tx nonce:   ", tx.Nonce())
 	fmt.Println("tx  to:      ", to)
-	fmt.Printf("tx  value:    %s wei (≈ %f ETH)\n", tx.Value().String(), weiToEth(tx.Value()))
+	fmt.Printf("tx  value:    %s wei (%s ETH)\n", tx.Value().String(), units.FormatEther(tx.Value()))
 	fmt.Println("tx  gasLimit:", tx.Gas())
 
 	// Legacy 交易：GasPrice 有值；EIP-1559：优先看 TipCap/FeeCap
@@ -138,16 +149,6 @@ func must(err error, where string) {
 	}
 }
 
-func weiToEth(wei *big.Int) float64 {
-	if wei == nil {
-		return 0
-	}
-	f := new(big.Float).SetInt(wei)                            // wei -> big.Float
-	eth := new(big.Float).Quo(f, big.NewFloat(math.Pow10(18))) // / 1e18
-	val, _ := eth.Float64()
-	return val
-}
-
 func short(s string, n int) string {
 	if len(s) <= n {
 		return s
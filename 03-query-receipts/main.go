@@ -12,6 +12,8 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
+
+	"example.com/ethclient-demo/pkg/units"
 )
 
 // 建议把密钥改为环境变量读取；这里为演示方便先写死
@@ -104,10 +106,10 @@ func printReceipt(r *types.Receipt) {
 	if r.EffectiveGasPrice != nil {
 		totalFeeWei := new(big.Int).Mul(r.EffectiveGasPrice, big.NewInt(int64(r.GasUsed)))
 		fmt.Printf("  gasUsed:           %d\n", r.GasUsed)
-		fmt.Printf("  effectiveGasPrice: %s wei (≈ %.9f ETH)\n",
-			r.EffectiveGasPrice.String(), weiToEth(r.EffectiveGasPrice))
-		fmt.Printf("  totalFee:          %s wei (≈ %.9f ETH)\n",
-			totalFeeWei.String(), weiToEth(totalFeeWei))
+		fmt.Printf("  effectiveGasPrice: %s wei (%s Gwei)\n",
+			r.EffectiveGasPrice.String(), units.FormatGwei(r.EffectiveGasPrice))
+		fmt.Printf("  totalFee:          %s wei (%s ETH)\n",
+			totalFeeWei.String(), units.FormatEther(totalFeeWei))
 	} else {
 		fmt.Printf("  gasUsed:           %d\n", r.GasUsed)
 		fmt.Println("  effectiveGasPrice: <nil>") // 极少见于旧数据或特殊客户端
@@ -128,13 +130,3 @@ func short(s string, n int) string {
 	}
 	return s[:n] + "…"
 }
-
-func weiToEth(wei *big.Int) float64 {
-	if wei == nil {
-		return 0
-	}
-	f := new(big.Float).SetInt(wei)
-	eth := new(big.Float).Quo(f, big.NewFloat(1e18))
-	val, _ := eth.Float64()
-	return val
-}
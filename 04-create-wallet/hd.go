@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"example.com/ethclient-demo/pkg/hdwallet"
+)
+
+// runHD 处理 HD 钱包子命令：
+//
+//	go run ./04-create-wallet hd new [words]   生成助记词（默认 12 词）并列出前几个账户
+//	go run ./04-create-wallet hd derive        从 MNEMONIC 派生账户
+//
+// 环境变量：MNEMONIC、MNEMONIC_PASS（BIP-39 口令，可空）、HD_PATH（默认 m/44'/60'/0'/0）、
+// START（起始索引，默认 0）、COUNT（个数，默认 5）、SHOW_PRIV=1 时打印私钥
+func runHD(args []string) {
+	if len(args) == 0 {
+		log.Fatal("usage: go run ./04-create-wallet hd new [words] | hd derive")
+	}
+	var mnemonic string
+	switch args[0] {
+	case "new":
+		words := 12
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil {
+				log.Fatalf("invalid word count: %s", args[1])
+			}
+			words = n
+		}
+		m, err := hdwallet.NewMnemonic(words)
+		if err != nil {
+			log.Fatalf("new mnemonic: %v", err)
+		}
+		mnemonic = m
+		// 【教学打印】助记词等同于全部私钥，生产环境请勿打印/泄露
+		fmt.Println("mnemonic:", mnemonic)
+	case "derive":
+		mnemonic = os.Getenv("MNEMONIC")
+		if mnemonic == "" {
+			log.Fatal("MNEMONIC not set")
+		}
+		if err := hdwallet.ValidateMnemonic(mnemonic); err != nil {
+			log.Fatalf("invalid mnemonic: %v", err)
+		}
+	default:
+		log.Fatalf("unknown hd command %q (want new | derive)", args[0])
+	}
+
+	base := getenv("HD_PATH", hdwallet.DefaultBasePath)
+	start := getenvInt("START", 0)
+	count := getenvInt("COUNT", 5)
+	showPriv := os.Getenv("SHOW_PRIV") == "1"
+	// 最后一级是非强化派生，索引必须 < 2^31；否则 Child 会悄悄变成强化派生（超过 2^32 还会回绕），打印的地址与路径对不上
+	if start > hdwallet.HardenedOffset || count > hdwallet.HardenedOffset-start {
+		log.Fatalf("START+COUNT must not exceed %d (got START=%d COUNT=%d)", hdwallet.HardenedOffset, start, count)
+	}
+
+	master, err := hdwallet.NewMaster(hdwallet.Seed(mnemonic, os.Getenv("MNEMONIC_PASS")))
+	if err != nil {
+		log.Fatalf("master key: %v", err)
+	}
+	// 基础路径只派生一次，之后每个账户只做最后一级非强化派生
+	parent, err := master.Derive(base)
+	if err != nil {
+		log.Fatalf("derive %s: %v", base, err)
+	}
+	fmt.Println("base path:", base)
+	fmt.Println("base xpub:", parent.Neuter().String())
+
+	for i := start; i < start+count; i++ {
+		child, err := parent.Child(uint32(i))
+		if err != nil {
+			log.Printf("skip %s/%d: %v", base, i, err)
+			continue
+		}
+		fmt.Printf("\n[%s/%d]\n", base, i)
+		fmt.Println("  address:  ", child.Address().Hex())
+		fmt.Println("  publicKey:", hexutil.Encode(crypto.FromECDSAPub(child.PublicKey()))[4:])
+		if showPriv {
+			priv, err := child.PrivateKey()
+			if err != nil {
+				log.Fatalf("private key: %v", err)
+			}
+			fmt.Println("  privateKey:", hexutil.Encode(crypto.FromECDSA(priv))[2:])
+		}
+	}
+}
+
+func getenv(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
+	}
+	return def
+}
+
+func getenvInt(k string, def int) int {
+	v := os.Getenv(k)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		log.Fatalf("invalid %s: %s", k, v)
+	}
+	return n
+}
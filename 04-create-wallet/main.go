@@ -4,6 +4,7 @@ import (
 	"crypto/ecdsa"
 	"fmt"
 	"log"
+	"os"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -13,6 +14,32 @@ import (
 )
 
 func main() {
+	// 子命令：hd（助记词 / HD 派生）、vanity（靓号地址）、sign / verify（EIP-191 消息签名）、sign712 / verify712（EIP-712 结构化数据签名）；不带参数时保持原来的单私钥演示
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "hd":
+			runHD(os.Args[2:])
+			return
+		case "vanity":
+			runVanity()
+			return
+		case "sign":
+			runSign(os.Args[2:])
+			return
+		case "verify":
+			runVerify(os.Args[2:])
+			return
+		case "sign712":
+			runSign712(os.Args[2:])
+			return
+		case "verify712":
+			runVerify712(os.Args[2:])
+			return
+		default:
+			log.Fatalf("unknown command %q (want hd | vanity | sign | verify | sign712 | verify712)", os.Args[1])
+		}
+	}
+
 	// ============= 1) 获取私钥 =============
 	// 方案 A：随机生成新的 ECDSA 私钥（secp256k1）
 	priv, err := crypto.GenerateKey()
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"os/signal"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// vanityMatcher 判断地址（不含 0x 的 40 位 hex）是否符合要求
+type vanityMatcher struct {
+	prefix, suffix string
+	re             *regexp.Regexp
+	caseSensitive  bool // true 时按 EIP-55 大小写比较（每个字母多一半的淘汰率）
+}
+
+var hexChars = regexp.MustCompile(`^[0-9a-fA-F]*$`)
+
+func newVanityMatcher(prefix, suffix, pattern string, caseSensitive bool) (*vanityMatcher, error) {
+	for _, s := range []string{prefix, suffix} {
+		if !hexChars.MatchString(s) {
+			return nil, fmt.Errorf("%q is not hex", s)
+		}
+	}
+	if len(prefix)+len(suffix) > 40 {
+		return nil, fmt.Errorf("prefix + suffix longer than an address")
+	}
+	m := &vanityMatcher{prefix: prefix, suffix: suffix, caseSensitive: caseSensitive}
+	if !caseSensitive {
+		m.prefix, m.suffix = strings.ToLower(prefix), strings.ToLower(suffix)
+	}
+	if pattern != "" {
+		if !caseSensitive {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("compile regex: %w", err)
+		}
+		m.re = re
+	}
+	return m, nil
+}
+
+// match 对公钥算地址并比较；不区分大小写时直接比小写 hex，省掉 EIP-55 的那次 Keccak
+func (m *vanityMatcher) match(pub *ecdsa.PublicKey) bool {
+	addr := crypto.PubkeyToAddress(*pub)
+	var s string
+	if m.caseSensitive {
+		s = addr.Hex()[2:]
+	} else {
+		s = hex.EncodeToString(addr[:])
+	}
+	if !strings.HasPrefix(s, m.prefix) || !strings.HasSuffix(s, m.suffix) {
+		return false
+	}
+	return m.re == nil || m.re.MatchString(s)
+}
+
+// difficulty 返回平均需要尝试的次数；有正则时无法估算，返回 0
+func (m *vanityMatcher) difficulty() float64 {
+	if m.re != nil {
+		return 0
+	}
+	d := math.Pow(16, float64(len(m.prefix)+len(m.suffix)))
+	if m.caseSensitive {
+		for _, c := range m.prefix + m.suffix {
+			if c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F' {
+				d *= 2 // EIP-55 中每个字母的大小写由哈希的一位决定
+			}
+		}
+	}
+	return d
+}
+
+// runVanity 多核搜索靓号地址：
+//
+//	VANITY_PREFIX=dead VANITY_SUFFIX=beef go run ./04-create-wallet vanity
+//
+// 环境变量：VANITY_PREFIX、VANITY_SUFFIX、VANITY_REGEX（匹配不含 0x 的地址）、
+// VANITY_CASE=1（按 EIP-55 大小写匹配）、VANITY_WORKERS（默认 CPU 核数）
+func runVanity() {
+	m, err := newVanityMatcher(
+		strings.TrimPrefix(os.Getenv("VANITY_PREFIX"), "0x"),
+		os.Getenv("VANITY_SUFFIX"),
+		os.Getenv("VANITY_REGEX"),
+		os.Getenv("VANITY_CASE") == "1",
+	)
+	if err != nil {
+		log.Fatalf("vanity: %v", err)
+	}
+	if m.prefix == "" && m.suffix == "" && m.re == nil {
+		log.Fatal("vanity: set VANITY_PREFIX / VANITY_SUFFIX / VANITY_REGEX")
+	}
+	workers := getenvInt("VANITY_WORKERS", runtime.NumCPU())
+	if workers == 0 {
+		workers = 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var attempts atomic.Uint64
+	found := make(chan *ecdsa.PrivateKey, 1)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				priv, err := crypto.GenerateKey()
+				if err != nil {
+					log.Printf("generate key: %v", err)
+					cancel()
+					return
+				}
+				attempts.Add(1)
+				if m.match(&priv.PublicKey) {
+					select {
+					case found <- priv:
+					default:
+					}
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+
+	diff := m.difficulty()
+	fmt.Printf("[Vanity] workers=%d difficulty=%s\n", workers, formatDifficulty(diff))
+	start := time.Now()
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	var result *ecdsa.PrivateKey
+loop:
+	for {
+		select {
+		case result = <-found:
+			break loop
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+			n := attempts.Load()
+			rate := float64(n) / time.Since(start).Seconds()
+			line := fmt.Sprintf("  tried=%d  rate=%.0f keys/s", n, rate)
+			if diff > 0 && rate > 0 {
+				// 平均耗时为 difficulty / rate；50% 概率在 ln2 倍平均耗时内找到
+				line += fmt.Sprintf("  eta(avg)=%s  eta(50%%)=%s", fmtETA(diff/rate), fmtETA(math.Ln2*diff/rate))
+			}
+			fmt.Println(line)
+		}
+	}
+	cancel()
+	wg.Wait()
+	if result == nil {
+		select {
+		case result = <-found: // 取消与找到同时发生
+		default:
+		}
+	}
+
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if result == nil {
+		fmt.Printf("[Stopped] tried=%d elapsed=%s, no match\n", attempts.Load(), elapsed)
+		return
+	}
+	fmt.Printf("[Found] tried=%d elapsed=%s\n", attempts.Load(), elapsed)
+	fmt.Println("  address (EIP-55):", crypto.PubkeyToAddress(result.PublicKey).Hex())
+	// 【教学打印】私钥 hex（生产环境请勿打印/泄露）
+	fmt.Println("  privateKey(hex): ", hexutil.Encode(crypto.FromECDSA(result))[2:])
+}
+
+func formatDifficulty(d float64) string {
+	if d == 0 {
+		return "unknown (regex)"
+	}
+	return fmt.Sprintf("%.0f", d)
+}
+
+// fmtETA 格式化预计耗时（秒）；超出 time.Duration 能表示的范围（约 292 年）时只给出下限
+func fmtETA(secs float64) string {
+	if secs >= math.MaxInt64/float64(time.Second) {
+		return ">292y"
+	}
+	return time.Duration(secs * float64(time.Second)).Round(time.Second).String()
+}
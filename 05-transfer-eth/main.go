@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"math"
 	"math/big"
+	"os"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
@@ -14,6 +16,10 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+
+	"example.com/ethclient-demo/pkg/feeoracle"
+	"example.com/ethclient-demo/pkg/nonce"
+	"example.com/ethclient-demo/pkg/units"
 )
 
 const rpcURL = "https://eth-sepolia.g.alchemy.com/v2/xxxx" // ← 换成你的 RPC
@@ -34,24 +40,33 @@ func main() {
 	from := crypto.PubkeyToAddress(priv.PublicKey)
 	fmt.Println("👤 from address:", from.Hex())
 
-	// 2) 链 ID 与 nonce
+	// 2) 链 ID；nonce 交给 nonce.Manager 在本地分配，连发多笔也不会重复
 	chainID, err := cli.ChainID(ctx)
 	must(err, "chain id")
-	nonce, err := cli.PendingNonceAt(ctx, from)
-	must(err, "pending nonce")
-	fmt.Println("🔢 nonce:", nonce, " chainID:", chainID)
+	fmt.Println("🔗 chainID:", chainID)
+	nonces := nonce.NewManager(cli)
+
+	// 连发几笔（环境变量 TX_COUNT，默认 1）
+	count := 1
+	if v := getenv("TX_COUNT", ""); v != "" {
+		count, err = strconv.Atoi(v)
+		if err != nil || count < 1 {
+			log.Fatalf("❌ invalid TX_COUNT: %s", v)
+		}
+	}
 
 	// 3) 转账目标与金额（改成 0.001 ETH）
 	to := common.HexToAddress("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d") // 换成你的收款地址
-	value := new(big.Int)
-	value.SetString("1000000000000000", 10) // 0.001 ETH = 1e15 wei
+	// 0.001 ETH = 1e15 wei；units 用整数运算换算，没有浮点误差
+	value, err := units.ParseEther("0.001")
+	must(err, "parse amount")
 
-	// 4) EIP-1559 费用参数（无需余额即可拿到建议费率）
-	tipCap, err := cli.SuggestGasTipCap(ctx)
-	must(err, "suggest tip cap")
-	baseLike, err := cli.SuggestGasPrice(ctx) // 兜底近似 baseFee
-	must(err, "suggest gas price")
-	feeCap := new(big.Int).Mul(baseLike, big.NewInt(2)) // 给个 2x 上限
+	// 4) EIP-1559 费用参数：feeoracle 基于 FeeHistory 给出（无需余额），档位由 FEE_SPEED 选择，默认 standard
+	speed, err := feeoracle.ParseSpeed(getenv("FEE_SPEED", "standard"))
+	must(err, "fee speed")
+	tipCap, feeCap, err := feeoracle.New(cli).Suggest(ctx, speed)
+	must(err, "suggest fees")
+	fmt.Printf("⛽ fees(%s): tip=%s wei  feeCap=%s wei\n", speed, tipCap, feeCap)
 
 	// 5) 纯转账的 gasLimit 固定 21000；避免没余额时 EstimateGas 报错
 	var gasLimit uint64 = 21000
@@ -65,44 +80,55 @@ func main() {
 		GasTipCap: tipCap,
 	})
 
-	// 6) 余额检查：需要 >= value + feeCap * gasLimit
+	// 6) 余额检查：需要 >= (value + feeCap * gasLimit) * count
 	bal, err := cli.BalanceAt(ctx, from, nil)
 	must(err, "balance check")
 	need := new(big.Int).Mul(feeCap, big.NewInt(int64(gasLimit)))
 	need.Add(need, value)
+	need.Mul(need, big.NewInt(int64(count)))
 
-	fmt.Printf("💳 balance = %s wei (≈ %.8f ETH)\n", bal, weiToEth(bal))
-	fmt.Printf("📌 required >= value(%s) + feeCap(%s)*gas(%d) = %s wei (≈ %.8f ETH)\n",
-		value, feeCap, gasLimit, need, weiToEth(need))
+	fmt.Printf("💳 balance = %s wei (%s ETH)\n", bal, units.FormatEther(bal))
+	fmt.Printf("📌 required >= (value(%s) + feeCap(%s)*gas(%d)) * %d = %s wei (%s ETH)\n",
+		value, feeCap, gasLimit, count, need, units.FormatEther(need))
 
 	if bal.Cmp(need) < 0 {
 		fmt.Println("❗余额不足：请先用 Sepolia faucet 给上面的 from 地址充值，然后重跑。")
 		return
 	}
 
-	// 7) 构造 EIP-1559 交易
-	tx := types.NewTx(&types.DynamicFeeTx{
-		ChainID:   chainID,
-		Nonce:     nonce,
-		To:        &to,
-		Value:     value,
-		Gas:       gasLimit,
-		GasTipCap: tipCap,
-		GasFeeCap: feeCap,
-	})
-
-	// 8) 签名并发送
+	// 7) 构造 EIP-1559 交易并签名（nonce 由 Manager 分配后传进来）
 	signer := types.LatestSignerForChainID(chainID)
-	signed, err := types.SignTx(tx, signer, priv)
-	must(err, "sign tx")
+	build := func(n uint64) (*types.Transaction, error) {
+		return types.SignTx(types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     n,
+			To:        &to,
+			Value:     value,
+			Gas:       gasLimit,
+			GasTipCap: tipCap,
+			GasFeeCap: feeCap,
+		}), signer, priv)
+	}
 
-	err = cli.SendTransaction(ctx, signed)
-	must(err, "send tx")
-	fmt.Println("🚀 tx sent:", signed.Hash().Hex())
+	// 8) 并发发送：广播失败的 nonce 会被回收，"nonce too low" 会重新同步后重试
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			signed, err := nonces.Send(ctx, from, build, cli.SendTransaction)
+			if err != nil {
+				log.Printf("❌ send tx: %v", err)
+				return
+			}
+			fmt.Printf("🚀 tx sent: %s (nonce=%d)\n", signed.Hash().Hex(), signed.Nonce())
+		}()
+	}
+	wg.Wait()
 
 	// 显示最大小费上限
 	maxFeeWei := new(big.Int).Mul(feeCap, big.NewInt(int64(gasLimit)))
-	fmt.Printf("💰 max fee cap = %s wei (≈ %.8f ETH)\n", maxFeeWei, weiToEth(maxFeeWei))
+	fmt.Printf("💰 max fee cap = %s wei (%s ETH)\n", maxFeeWei, units.FormatEther(maxFeeWei))
 }
 
 func must(err error, where string) {
@@ -111,12 +137,9 @@ func must(err error, where string) {
 	}
 }
 
-func weiToEth(wei *big.Int) float64 {
-	if wei == nil {
-		return 0
+func getenv(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
 	}
-	f := new(big.Float).SetInt(wei)
-	eth := new(big.Float).Quo(f, big.NewFloat(1e18))
-	val, _ := eth.Float64()
-	return math.Round(val*1e8) / 1e8
+	return def
 }
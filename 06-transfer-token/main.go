@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"fmt"
 	"log"
 	"math/big"
@@ -13,8 +12,11 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+
+	"example.com/ethclient-demo/pkg/feeoracle"
+	"example.com/ethclient-demo/pkg/nonce"
+	"example.com/ethclient-demo/pkg/wallet"
 )
 
 // 建议把密钥改为环境变量读取；这里为演示方便先写死
@@ -41,35 +43,28 @@ func main() {
 		log.Fatal(err)
 	}
 
-	privateKey, err := crypto.HexToECDSA("<你的私钥HEX>")
+	// 设置 KEYSTORE_DIR 时用 keystore 账户签名（口令见 KEYSTORE_PASS），否则退回下面的明文私钥
+	key, err := wallet.LoadSigner("<你的私钥HEX>")
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		log.Fatal("cannot assert type: publicKey is not of type *ecdsa.PublicKey")
-	}
-
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
+	fromAddress := key.Address
 	fmt.Printf("%-26s %s\n", "fromAddress:", fromAddress.Hex())
 
-	// 用 PendingNonceAt 拿下一笔交易的 nonce
-	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Printf("%-26s %d\n", "nonce:", nonce)
+	// nonce 交给 nonce.Manager：首次分配时用 PendingNonceAt 同步，之后本地递增，连发多笔不会撞号
+	nonces := nonce.NewManager(client)
 
 	printTitle("STEP 2. 交易外层参数")
 	// value=0：代币转账不需要转原生 ETH。
 	value := big.NewInt(0) // 0 ETH
-	gasPrice, err := client.SuggestGasPrice(context.Background())
+	// EIP-1559 费用：feeoracle 基于最近区块的 FeeHistory 给出 tip 与 feeCap
+	tipCap, feeCap, err := feeoracle.New(client).Suggest(context.Background(), feeoracle.Standard)
 	if err != nil {
 		log.Fatal(err)
 	}
-	printBig("suggested gasPrice", gasPrice)
+	printBig("suggested tipCap", tipCap)
+	printBig("suggested feeCap", feeCap)
 
 	// 收款人（拿到代币的人）
 	toAddress := common.HexToAddress("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d")
@@ -122,22 +117,31 @@ func main() {
 	fmt.Printf("%-26s %d\n", "estimated gasLimit", gasLimit)
 
 	printTitle("STEP 5. 构造并签名交易")
-	tx := types.NewTransaction(nonce, tokenAddress, value, gasLimit, gasPrice, data)
-
 	chainID, err := client.NetworkID(context.Background())
 	if err != nil {
 		log.Fatal(err)
 	}
 	fmt.Printf("%-26s %s\n", "chainID", chainID.String())
 
-	// 拿 chainID，用 EIP-155 签名生成 signedTx。
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
-	if err != nil {
-		log.Fatal(err)
+	// 拿 chainID 构造动态费交易并签名生成 signedTx；nonce 由 Manager 分配后传进来
+	build := func(n uint64) (*types.Transaction, error) {
+		fmt.Printf("%-26s %d\n", "nonce:", n)
+		tx := types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     n,
+			To:        &tokenAddress,
+			Value:     value,
+			Gas:       gasLimit,
+			GasTipCap: tipCap,
+			GasFeeCap: feeCap,
+			Data:      data,
+		})
+		return key.SignTx(tx, chainID)
 	}
 
 	printTitle("STEP 6. 发送交易")
-	err = client.SendTransaction(context.Background(), signedTx)
+	// 广播失败的 nonce 会被回收；"nonce too low" 时重新同步换号重试
+	signedTx, err := nonces.Send(context.Background(), fromAddress, build, client.SendTransaction)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -5,15 +5,32 @@ import (
 	"fmt"
 	"log"
 	"math/big"
+	"os"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
+
+	"example.com/ethclient-demo/pkg/units"
 )
 
 func main() {
+	// 子命令：report <地址|地址文件>... 多账户 × 多区块余额报表；timeline <地址> <from> [to] 余额变化时间线；
+	// 不带参数时保持原来的单账户演示
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "report":
+			runReport(os.Args[2:])
+		case "timeline":
+			runTimeline(os.Args[2:])
+		default:
+			log.Fatalf("unknown command %q (want report | timeline)", os.Args[1])
+		}
+		return
+	}
+
 	// 1) 建立连接
-	client, err := ethclient.Dial("https://eth-sepolia.g.alchemy.com/v2/xxxx")
+	client, err := ethclient.Dial(defaultRPC)
 	if err != nil {
 		log.Fatalf("[ERR] ethclient.Dial: %v", err)
 	}
@@ -50,8 +67,8 @@ func main() {
 
 // printBalance 统一打印：标签、地址、区块、高精度与简洁 ETH、以及原始 wei。
 func printBalance(tag string, addr common.Address, blockNumber *big.Int, wei *big.Int) {
-	ethPrecise18 := weiToEth(wei, 18) // 精确显示 18 位小数
-	ethPretty6 := weiToEth(wei, 6)    // 常用显示 6 位小数
+	ethPrecise18 := units.FormatFixed(wei, units.Ether, 18) // 精确显示 18 位小数
+	ethPretty6 := units.FormatFixed(wei, units.Ether, 6)    // 常用显示 6 位小数（四舍五入）
 
 	if blockNumber != nil {
 		fmt.Printf("[%s] address=%s  block=%s\n", tag, addr.Hex(), blockNumber.String())
@@ -63,20 +80,3 @@ func printBalance(tag string, addr common.Address, blockNumber *big.Int, wei *bi
 	fmt.Printf("  - balance(ETH, precise 18dp): %s\n", ethPrecise18)
 	fmt.Printf("  - balance(ETH, pretty 6dp):   %s\n\n", ethPretty6)
 }
-
-// weiToEth 将 wei 转为 ETH 的字符串表示，scale 指定小数位数（例如 18 或 6）
-func weiToEth(wei *big.Int, scale int) string {
-	if wei == nil {
-		return "0"
-	}
-	// denom = 10^18
-	denom := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
-
-	// f = wei / 10^18，使用 big.Float 保证大数精度
-	fWei := new(big.Float).SetInt(wei)
-	fDen := new(big.Float).SetInt(denom)
-	fEth := new(big.Float).Quo(fWei, fDen)
-
-	// 返回固定小数位的字符串
-	return fEth.Text('f', scale)
-}
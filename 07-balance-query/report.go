@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"example.com/ethclient-demo/pkg/units"
+)
+
+const defaultRPC = "https://eth-sepolia.g.alchemy.com/v2/xxxx"
+
+// runReport 多账户 × 多区块余额报表：
+//
+//	BLOCKS=5532993,latest go run ./07-balance-query report 0xabc... 0xdef...
+//	BLOCKS=5000000,5500000,latest OUTPUT=csv go run ./07-balance-query report accounts.txt > balances.csv
+//
+// 参数是地址或地址文件（每行一个，# 开头为注释，逗号 / 空白后的内容忽略）。
+// 环境变量：RPC_URL、BLOCKS（逗号分隔的区块号 / latest / pending / safe / finalized / earliest，默认 latest）、
+// OUTPUT（table | csv | json，默认 table）、BATCH（每个批量请求的 eth_getBalance 数，默认 100）。
+// latest 会先解析成具体区块号，保证同一列的余额来自同一个区块；相邻两列之间给出每个账户的差额。
+func runReport(args []string) {
+	addrs, err := readAddresses(args)
+	mustOK("addresses", err)
+	if len(addrs) == 0 {
+		log.Fatalf("usage: BLOCKS=<n,latest,...> go run ./07-balance-query report <address|file>...")
+	}
+	output := getenv("OUTPUT", "table")
+	if output != "table" && output != "csv" && output != "json" {
+		log.Fatalf("[ERR] invalid OUTPUT: %s (want table | csv | json)", output)
+	}
+	batch, err := strconv.Atoi(getenv("BATCH", "100"))
+	if err != nil || batch < 1 {
+		log.Fatalf("[ERR] invalid BATCH: %s", os.Getenv("BATCH"))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	client, err := ethclient.DialContext(ctx, getenv("RPC_URL", defaultRPC))
+	mustOK("ethclient.Dial", err)
+	defer client.Close()
+
+	blocks, err := parseBlocks(ctx, client, getenv("BLOCKS", "latest"))
+	mustOK("BLOCKS", err)
+	bal, err := fetchBalances(ctx, client.Client(), addrs, blocks, batch)
+	mustOK("eth_getBalance", err)
+
+	switch output {
+	case "csv":
+		mustOK("write csv", writeCSV(addrs, blocks, bal))
+	case "json":
+		mustOK("write json", writeJSON(addrs, blocks, bal))
+	default:
+		printMatrix(addrs, blocks, bal)
+	}
+}
+
+// blockRef 是报表的一列：Label 用于显示，Param 是传给 eth_getBalance 的区块参数
+type blockRef struct {
+	Label string
+	Param string
+}
+
+func parseBlocks(ctx context.Context, client *ethclient.Client, spec string) ([]blockRef, error) {
+	var out []blockRef
+	var head *uint64
+	for _, s := range strings.Split(spec, ",") {
+		s = strings.ToLower(strings.TrimSpace(s))
+		switch s {
+		case "":
+			continue
+		case "latest":
+			if head == nil {
+				n, err := client.BlockNumber(ctx)
+				if err != nil {
+					return nil, err
+				}
+				head = &n
+			}
+			out = append(out, blockRef{Label: strconv.FormatUint(*head, 10), Param: hexutil.EncodeUint64(*head)})
+		case "pending", "safe", "finalized", "earliest":
+			out = append(out, blockRef{Label: s, Param: s})
+		default:
+			n, err := strconv.ParseUint(s, 0, 64) // 支持十进制和 0x 十六进制
+			if err != nil {
+				return nil, fmt.Errorf("invalid block %q", s)
+			}
+			out = append(out, blockRef{Label: strconv.FormatUint(n, 10), Param: hexutil.EncodeUint64(n)})
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no blocks in %q", spec)
+	}
+	return out, nil
+}
+
+// readAddresses 解析命令行：地址直接使用，其余当作地址文件读取；按首次出现的顺序去重
+func readAddresses(args []string) ([]common.Address, error) {
+	var out []common.Address
+	seen := make(map[common.Address]bool)
+	add := func(a common.Address) {
+		if !seen[a] {
+			seen[a] = true
+			out = append(out, a)
+		}
+	}
+	for _, arg := range args {
+		if common.IsHexAddress(arg) {
+			add(common.HexToAddress(arg))
+			continue
+		}
+		f, err := os.Open(arg)
+		if err != nil {
+			return nil, err
+		}
+		sc := bufio.NewScanner(f)
+		for line := 1; sc.Scan(); line++ {
+			text := strings.TrimSpace(sc.Text())
+			if text == "" || strings.HasPrefix(text, "#") {
+				continue
+			}
+			// 每行取第一列，后面可以跟逗号 / 空白分隔的备注；只有分隔符的行没有第一列
+			fields := strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+			if len(fields) == 0 || !common.IsHexAddress(fields[0]) {
+				f.Close()
+				return nil, fmt.Errorf("%s:%d: invalid address %q", arg, line, text)
+			}
+			add(common.HexToAddress(fields[0]))
+		}
+		f.Close()
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// fetchBalances 把 len(addrs) × len(blocks) 个 eth_getBalance 分批放进 JSON-RPC batch；结果按 [账户][区块] 排列
+func fetchBalances(ctx context.Context, c *rpc.Client, addrs []common.Address, blocks []blockRef, batch int) ([][]*big.Int, error) {
+	results := make([]hexutil.Big, len(addrs)*len(blocks))
+	elems := make([]rpc.BatchElem, len(results))
+	for i, a := range addrs {
+		for j, b := range blocks {
+			k := i*len(blocks) + j
+			elems[k] = rpc.BatchElem{Method: "eth_getBalance", Args: []any{a, b.Param}, Result: &results[k]}
+		}
+	}
+	for lo := 0; lo < len(elems); lo += batch {
+		hi := min(lo+batch, len(elems))
+		if err := c.BatchCallContext(ctx, elems[lo:hi]); err != nil {
+			return nil, err
+		}
+		for k := lo; k < hi; k++ {
+			if err := elems[k].Error; err != nil {
+				return nil, fmt.Errorf("%s @ block %s: %w", addrs[k/len(blocks)].Hex(), blocks[k%len(blocks)].Label, err)
+			}
+		}
+	}
+	out := make([][]*big.Int, len(addrs))
+	for i := range addrs {
+		out[i] = make([]*big.Int, len(blocks))
+		for j := range blocks {
+			out[i][j] = results[i*len(blocks)+j].ToInt()
+		}
+	}
+	return out, nil
+}
+
+// deltaLabel 是相邻两列之间差额的列名（纯 ASCII，表格按字节宽度对齐）
+func deltaLabel(blocks []blockRef, j int) string {
+	return "delta@" + blocks[j-1].Label + "->" + blocks[j].Label
+}
+
+func printMatrix(addrs []common.Address, blocks []blockRef, bal [][]*big.Int) {
+	fmt.Printf("[Balance/report] accounts=%d blocks=%d (ETH)\n\n", len(addrs), len(blocks))
+	fmt.Printf("%-42s", "address")
+	for _, b := range blocks {
+		fmt.Printf("  %24s", b.Label)
+	}
+	for j := 1; j < len(blocks); j++ {
+		fmt.Printf("  %24s", deltaLabel(blocks, j))
+	}
+	fmt.Println()
+
+	totals := make([]*big.Int, len(blocks))
+	for j := range totals {
+		totals[j] = new(big.Int)
+	}
+	for i, a := range addrs {
+		fmt.Printf("%-42s", a.Hex())
+		for j, v := range bal[i] {
+			fmt.Printf("  %24s", units.FormatEther(v))
+			totals[j].Add(totals[j], v)
+		}
+		for j := 1; j < len(blocks); j++ {
+			fmt.Printf("  %24s", signed(new(big.Int).Sub(bal[i][j], bal[i][j-1])))
+		}
+		fmt.Println()
+	}
+	fmt.Printf("%-42s", "TOTAL")
+	for _, t := range totals {
+		fmt.Printf("  %24s", units.FormatEther(t))
+	}
+	for j := 1; j < len(blocks); j++ {
+		fmt.Printf("  %24s", signed(new(big.Int).Sub(totals[j], totals[j-1])))
+	}
+	fmt.Println()
+}
+
+// writeCSV 输出一行一个账户：各区块余额与相邻区块差额，单位 ETH（精确十进制，不丢精度）
+func writeCSV(addrs []common.Address, blocks []blockRef, bal [][]*big.Int) error {
+	w := csv.NewWriter(os.Stdout)
+	header := []string{"address"}
+	for _, b := range blocks {
+		header = append(header, "balance@"+b.Label)
+	}
+	for j := 1; j < len(blocks); j++ {
+		header = append(header, deltaLabel(blocks, j))
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for i, a := range addrs {
+		row := []string{a.Hex()}
+		for _, v := range bal[i] {
+			row = append(row, units.FormatEther(v))
+		}
+		for j := 1; j < len(blocks); j++ {
+			row = append(row, units.FormatEther(new(big.Int).Sub(bal[i][j], bal[i][j-1])))
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+type amountJSON struct {
+	Wei string `json:"wei"`
+	ETH string `json:"eth"`
+}
+
+type balanceJSON struct {
+	Block string `json:"block"`
+	amountJSON
+}
+
+type deltaJSON struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	amountJSON
+}
+
+type accountJSON struct {
+	Address  string        `json:"address"`
+	Balances []balanceJSON `json:"balances"`
+	Deltas   []deltaJSON   `json:"deltas,omitempty"`
+}
+
+// writeJSON 输出账户数组；金额同时给出 wei 与 ETH 两种十进制字符串（避免 JS 数字精度问题）
+func writeJSON(addrs []common.Address, blocks []blockRef, bal [][]*big.Int) error {
+	amount := func(v *big.Int) amountJSON { return amountJSON{Wei: v.String(), ETH: units.FormatEther(v)} }
+	out := make([]accountJSON, len(addrs))
+	for i, a := range addrs {
+		out[i].Address = a.Hex()
+		for j, v := range bal[i] {
+			out[i].Balances = append(out[i].Balances, balanceJSON{Block: blocks[j].Label, amountJSON: amount(v)})
+		}
+		for j := 1; j < len(blocks); j++ {
+			d := new(big.Int).Sub(bal[i][j], bal[i][j-1])
+			out[i].Deltas = append(out[i].Deltas, deltaJSON{From: blocks[j-1].Label, To: blocks[j].Label, amountJSON: amount(d)})
+		}
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// signed 给非负差额加上 + 号，方便一眼区分流入 / 流出
+func signed(v *big.Int) string {
+	s := units.FormatEther(v)
+	if v.Sign() > 0 {
+		s = "+" + s
+	}
+	return s
+}
+
+func mustOK(tag string, err error) {
+	if err != nil {
+		log.Fatalf("[ERR] %s: %v", tag, err)
+	}
+}
+
+func getenv(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
+	}
+	return def
+}
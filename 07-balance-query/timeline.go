@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"example.com/ethclient-demo/pkg/units"
+)
+
+// runTimeline 找出账户在区块区间内每一次 ETH 余额变化：
+//
+//	go run ./07-balance-query timeline <address> <fromBlock> [toBlock]
+//
+// 对区间二分：两端状态相同就认为中间没有变化，否则取中点继续拆，直到相邻两块，
+// 每个变化点约需 log2(区间长度) 次查询。状态同时比较余额和 nonce，余额“转出又转回”时 nonce 也会变，
+// 不会被漏掉（纯转入后又被合约原样转走这类情况仍无法发现）。
+// 查询历史状态需要归档节点（archive node），普通全节点只保留最近约 128 个区块的状态。
+// 环境变量：RPC_URL、OUTPUT（table | csv | json）。
+func runTimeline(args []string) {
+	if len(args) < 2 || len(args) > 3 || !common.IsHexAddress(args[0]) {
+		log.Fatalf("usage: go run ./07-balance-query timeline <address> <fromBlock> [toBlock]")
+	}
+	addr := common.HexToAddress(args[0])
+	from, err := strconv.ParseUint(args[1], 10, 64)
+	mustOK("parse fromBlock", err)
+	output := getenv("OUTPUT", "table")
+	if output != "table" && output != "csv" && output != "json" {
+		log.Fatalf("[ERR] invalid OUTPUT: %s (want table | csv | json)", output)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	client, err := ethclient.DialContext(ctx, getenv("RPC_URL", defaultRPC))
+	mustOK("ethclient.Dial", err)
+	defer client.Close()
+
+	var to uint64
+	if len(args) == 3 {
+		to, err = strconv.ParseUint(args[2], 10, 64)
+		mustOK("parse toBlock", err)
+	} else {
+		to, err = client.BlockNumber(ctx)
+		mustOK("BlockNumber", err)
+	}
+	if from > to {
+		log.Fatalf("[ERR] fromBlock %d > toBlock %d", from, to)
+	}
+
+	s := &bisector{src: client, addr: addr, cache: make(map[uint64]acctState)}
+	start, changes, err := s.run(ctx, from, to)
+	mustOK("search balance changes (historical state needs an archive node)", err)
+	for i := range changes {
+		h, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(changes[i].Block))
+		mustOK("HeaderByNumber", err)
+		changes[i].Time = time.Unix(int64(h.Time), 0).UTC()
+	}
+
+	switch output {
+	case "csv":
+		mustOK("write csv", writeTimelineCSV(changes))
+	case "json":
+		mustOK("write json", writeTimelineJSON(addr, from, to, start, changes))
+	default:
+		fmt.Printf("[Balance/timeline] address=%s range=[%d, %d] changes=%d queries=%d\n",
+			addr.Hex(), from, to, len(changes), s.queries)
+		fmt.Printf("  start balance @%d: %s ETH\n\n", from, units.FormatEther(start))
+		fmt.Printf("%10s  %-20s  %24s  %24s  %24s\n", "block", "time (UTC)", "old (ETH)", "new (ETH)", "delta (ETH)")
+		for _, c := range changes {
+			fmt.Printf("%10d  %-20s  %24s  %24s  %24s\n", c.Block, c.Time.Format(time.DateTime),
+				units.FormatEther(c.Old), units.FormatEther(c.New), signed(c.Delta()))
+		}
+	}
+}
+
+// stateSource 是二分查找需要的最小接口（*ethclient.Client 满足），便于用假的归档节点替换
+type stateSource interface {
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+}
+
+type acctState struct {
+	balance *big.Int
+	nonce   uint64
+}
+
+func (s acctState) equal(o acctState) bool {
+	return s.nonce == o.nonce && s.balance.Cmp(o.balance) == 0
+}
+
+// balanceChange 表示在 Block 这个区块执行后余额从 Old 变为 New
+type balanceChange struct {
+	Block uint64
+	Time  time.Time
+	Old   *big.Int
+	New   *big.Int
+}
+
+func (c balanceChange) Delta() *big.Int { return new(big.Int).Sub(c.New, c.Old) }
+
+type bisector struct {
+	src     stateSource
+	addr    common.Address
+	cache   map[uint64]acctState
+	queries int
+}
+
+// run 返回 from 处的余额与 (from, to] 内按区块升序的全部余额变化
+func (b *bisector) run(ctx context.Context, from, to uint64) (*big.Int, []balanceChange, error) {
+	lo, err := b.state(ctx, from)
+	if err != nil {
+		return nil, nil, err
+	}
+	hi, err := b.state(ctx, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	var out []balanceChange
+	if err := b.search(ctx, from, to, lo, hi, &out); err != nil {
+		return nil, nil, err
+	}
+	return lo.balance, out, nil
+}
+
+func (b *bisector) search(ctx context.Context, lo, hi uint64, sLo, sHi acctState, out *[]balanceChange) error {
+	if lo >= hi || sLo.equal(sHi) {
+		return nil
+	}
+	if hi == lo+1 {
+		if sLo.balance.Cmp(sHi.balance) != 0 { // 只有 nonce 变化（如 0 手续费交易）不算余额变化
+			*out = append(*out, balanceChange{Block: hi, Old: sLo.balance, New: sHi.balance})
+		}
+		return nil
+	}
+	mid := lo + (hi-lo)/2
+	sMid, err := b.state(ctx, mid)
+	if err != nil {
+		return err
+	}
+	if err := b.search(ctx, lo, mid, sLo, sMid, out); err != nil {
+		return err
+	}
+	return b.search(ctx, mid, hi, sMid, sHi, out)
+}
+
+func (b *bisector) state(ctx context.Context, n uint64) (acctState, error) {
+	if s, ok := b.cache[n]; ok {
+		return s, nil
+	}
+	num := new(big.Int).SetUint64(n)
+	bal, err := b.src.BalanceAt(ctx, b.addr, num)
+	if err != nil {
+		return acctState{}, fmt.Errorf("BalanceAt(%d): %w", n, err)
+	}
+	nonce, err := b.src.NonceAt(ctx, b.addr, num)
+	if err != nil {
+		return acctState{}, fmt.Errorf("NonceAt(%d): %w", n, err)
+	}
+	b.queries += 2
+	s := acctState{balance: bal, nonce: nonce}
+	b.cache[n] = s
+	return s, nil
+}
+
+func writeTimelineCSV(changes []balanceChange) error {
+	w := csv.NewWriter(os.Stdout)
+	if err := w.Write([]string{"block", "timestamp", "old_eth", "new_eth", "delta_eth"}); err != nil {
+		return err
+	}
+	for _, c := range changes {
+		row := []string{
+			strconv.FormatUint(c.Block, 10), c.Time.Format(time.RFC3339),
+			units.FormatEther(c.Old), units.FormatEther(c.New), units.FormatEther(c.Delta()),
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func writeTimelineJSON(addr common.Address, from, to uint64, start *big.Int, changes []balanceChange) error {
+	type changeJSON struct {
+		Block     uint64     `json:"block"`
+		Timestamp time.Time  `json:"timestamp"`
+		Old       amountJSON `json:"old"`
+		New       amountJSON `json:"new"`
+		Delta     amountJSON `json:"delta"`
+	}
+	amount := func(v *big.Int) amountJSON { return amountJSON{Wei: v.String(), ETH: units.FormatEther(v)} }
+	out := struct {
+		Address string       `json:"address"`
+		From    uint64       `json:"fromBlock"`
+		To      uint64       `json:"toBlock"`
+		Start   amountJSON   `json:"startBalance"`
+		Changes []changeJSON `json:"changes"`
+	}{Address: addr.Hex(), From: from, To: to, Start: amount(start), Changes: []changeJSON{}}
+	for _, c := range changes {
+		out.Changes = append(out.Changes, changeJSON{
+			Block: c.Block, Timestamp: c.Time, Old: amount(c.Old), New: amount(c.New), Delta: amount(c.Delta()),
+		})
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
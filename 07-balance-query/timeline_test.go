@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"math/bits"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fakeArchive 是归档节点的替身：blocks 记录每个发生变化的区块执行后的状态，其余区块沿用之前的状态
+type fakeArchive struct {
+	genesis acctState
+	blocks  map[uint64]acctState
+}
+
+func (f *fakeArchive) at(n uint64) acctState {
+	s, best := f.genesis, uint64(0)
+	found := false
+	for b, st := range f.blocks {
+		if b <= n && (!found || b > best) {
+			s, best, found = st, b, true
+		}
+	}
+	return s
+}
+
+func (f *fakeArchive) BalanceAt(ctx context.Context, a common.Address, n *big.Int) (*big.Int, error) {
+	return new(big.Int).Set(f.at(n.Uint64()).balance), nil
+}
+
+func (f *fakeArchive) NonceAt(ctx context.Context, a common.Address, n *big.Int) (uint64, error) {
+	return f.at(n.Uint64()).nonce, nil
+}
+
+func st(balance int64, nonce uint64) acctState {
+	return acctState{balance: big.NewInt(balance), nonce: nonce}
+}
+
+func TestBisector(t *testing.T) {
+	const from, to = 1000, 1000 + 1<<16
+	depth := bits.Len64(to - from) // 每个变化点大约需要的二分层数
+
+	for _, c := range []struct {
+		name   string
+		blocks map[uint64]acctState
+	}{
+		{"no changes", nil},
+		{"change at from+1", map[uint64]acctState{from + 1: st(90, 1)}},
+		{"change at to", map[uint64]acctState{to: st(150, 0)}},
+		{"changes in one half", map[uint64]acctState{
+			from + 10: st(80, 1), from + 11: st(70, 2), from + 500: st(75, 2), from + 30000: st(10, 3),
+		}},
+		// 只有转入：nonce 不变，只能靠余额比较发现
+		{"incoming only", map[uint64]acctState{from + 4242: st(1100, 0), from + 60000: st(2100, 0)}},
+		// 转出又转回：余额回到原值，但 nonce 变了，二分不会在外层提前剪枝
+		{"round trip", map[uint64]acctState{from + 100: st(50, 1), from + 200: st(100, 1)}},
+		// 变化发生在 from 之前不应被报告
+		{"before range", map[uint64]acctState{from - 1: st(7, 9)}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			src := &fakeArchive{genesis: st(100, 0), blocks: c.blocks}
+			b := &bisector{src: src, addr: common.HexToAddress("0x1"), cache: make(map[uint64]acctState)}
+			start, changes, err := b.run(context.Background(), from, to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if start.Cmp(src.at(from).balance) != 0 {
+				t.Errorf("start = %v, want %v", start, src.at(from).balance)
+			}
+
+			var want []uint64
+			for n := range c.blocks {
+				if n > from && n <= to && src.at(n).balance.Cmp(src.at(n-1).balance) != 0 {
+					want = append(want, n)
+				}
+			}
+			sort.Slice(want, func(i, j int) bool { return want[i] < want[j] })
+			if len(changes) != len(want) {
+				t.Fatalf("changes = %d, want %d (%v)", len(changes), len(want), want)
+			}
+			for i, ch := range changes {
+				if ch.Block != want[i] {
+					t.Errorf("change %d at block %d, want %d", i, ch.Block, want[i])
+				}
+				if ch.Old.Cmp(src.at(ch.Block-1).balance) != 0 || ch.New.Cmp(src.at(ch.Block).balance) != 0 {
+					t.Errorf("block %d: %v -> %v", ch.Block, ch.Old, ch.New)
+				}
+			}
+
+			// 两端 2 个状态 + 每个状态变化点至多 depth 个中点；每个状态是 BalanceAt + NonceAt 两次查询
+			maxStates := 2 + len(c.blocks)*depth
+			if b.queries > 2*maxStates {
+				t.Errorf("queries = %d, want <= %d", b.queries, 2*maxStates)
+			}
+		})
+	}
+}
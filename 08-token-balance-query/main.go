@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"math/big"
+	"os"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -12,11 +13,26 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 
 	token "example.com/ethclient-demo/08-token-balance-query/erc20" // for demo: 由 abigen 生成的本地包
+	"example.com/ethclient-demo/pkg/units"
 )
 
 func main() {
+	// 子命令：multi 用 Multicall3 一次读取多个代币 × 多个持有人；portfolio 按代币列表汇总持仓；
+	// 不带参数时保持原来的单代币演示
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "multi":
+			runMulti()
+		case "portfolio":
+			runPortfolio(os.Args[2:])
+		default:
+			log.Fatalf("unknown command %q (want multi | portfolio)", os.Args[1])
+		}
+		return
+	}
+
 	// 1) 连接以太坊节点
-	client, err := ethclient.Dial("https://eth-sepolia.g.alchemy.com/v2/xxx")
+	client, err := ethclient.Dial(defaultRPC)
 	if err != nil {
 		log.Fatalf("[ERR] ethclient.Dial: %v", err)
 	}
@@ -73,12 +89,12 @@ func printTokenHeader(name, symbol string, decimals uint8, tokenAddr common.Addr
 
 // 余额打印：显示地址、wei、十进制（精确与易读）
 func printBalance(tag string, addr common.Address, wei *big.Int, decimals uint8, symbol string) {
-	precise := weiToDecimalString(wei, decimals, int(decimals)) // 精确：按代币 decimals 位
+	precise := units.FormatFixed(wei, int(decimals), int(decimals)) // 精确：按代币 decimals 位
 	prettyScale := 6
 	if int(decimals) < prettyScale {
 		prettyScale = int(decimals)
 	}
-	pretty := weiToDecimalString(wei, decimals, prettyScale) // 易读：默认 6 位（不足则取 decimals）
+	pretty := units.FormatFixed(wei, int(decimals), prettyScale) // 易读：默认 6 位（不足则取 decimals，四舍五入）
 
 	fmt.Printf("[%s] address: %s (%s)\n", tag, addr.Hex(), shortHex(addr.Hex()))
 	fmt.Printf("  - balance(wei):           %s\n", wei.String())
@@ -86,23 +102,6 @@ func printBalance(tag string, addr common.Address, wei *big.Int, decimals uint8,
 	fmt.Printf("  - balance(%s, pretty %ddp): %s\n\n", symbol, prettyScale, pretty)
 }
 
-// 将 wei 转换为十进制字符串（scale 是输出小数位数）
-func weiToDecimalString(wei *big.Int, decimals uint8, scale int) string {
-	if wei == nil {
-		return "0"
-	}
-	// denom = 10^decimals（用 big.Int 精确表示）
-	denom := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
-
-	// fEth = wei / 10^decimals
-	fWei := new(big.Float).SetInt(wei)
-	fDen := new(big.Float).SetInt(denom)
-	fEth := new(big.Float).Quo(fWei, fDen)
-
-	// 固定小数位输出
-	return fEth.Text('f', scale)
-}
-
 // 简写 0x 地址：0x1234...ABCD
 func shortHex(hexAddr string) string {
 	if len(hexAddr) <= 12 {
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	token "example.com/ethclient-demo/08-token-balance-query/erc20"
+	"example.com/ethclient-demo/pkg/multicall"
+	"example.com/ethclient-demo/pkg/units"
+)
+
+const defaultRPC = "https://eth-sepolia.g.alchemy.com/v2/xxx"
+
+// runMulti 一次 Multicall3 调用读取多个代币的元信息和多个持有人的余额：
+//
+//	TOKENS=0xfade...,0x1c7d... HOLDERS=0x2583...,0x70997... go run ./08-token-balance-query multi
+//
+// 环境变量：RPC_URL、TOKENS / HOLDERS（逗号分隔）、MULTICALL（Multicall3 地址；off 表示不用，直接并发逐个调用）、
+// BATCH（每次 aggregate3 的子调用数）。单个代币调用失败（非 ERC-20、没有 name() 等）只影响对应的格子。
+func runMulti() {
+	tokens := parseAddressList("TOKENS")
+	holders := parseAddressList("HOLDERS")
+	if len(tokens) == 0 || len(holders) == 0 {
+		log.Fatalf("usage: TOKENS=<addr,...> HOLDERS=<addr,...> go run ./08-token-balance-query multi")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client, err := ethclient.DialContext(ctx, getenv("RPC_URL", defaultRPC))
+	mustOK("ethclient.Dial", err)
+	defer client.Close()
+
+	mc := newMulticall(client)
+	start := time.Now()
+	infos, err := fetchTokenInfo(ctx, mc, tokens)
+	mustOK("fetch token info", err)
+	bals, err := fetchBalances(ctx, mc, tokens, holders)
+	mustOK("fetch balances", err)
+	via := "multicall3 aggregate3"
+	if ok, _ := mc.Available(ctx); !ok {
+		via = "parallel eth_call (no Multicall3)"
+	}
+
+	fmt.Printf("[Token/multi] tokens=%d holders=%d calls=%d via %s in %s\n\n",
+		len(tokens), len(holders), len(tokens)*(3+len(holders)), via, time.Since(start).Round(time.Millisecond))
+	for _, t := range infos {
+		if t.Err != nil {
+			fmt.Printf("  %s  <%v>\n", t.Address.Hex(), t.Err)
+			continue
+		}
+		fmt.Printf("  %s  %-8s decimals=%-2d %s\n", t.Address.Hex(), t.Symbol, t.Decimals, t.Name)
+	}
+	fmt.Println()
+
+	fmt.Printf("%-42s", "holder")
+	for _, t := range infos {
+		fmt.Printf("  %24s", t.label())
+	}
+	fmt.Println()
+	for i, h := range holders {
+		fmt.Printf("%-42s", h.Hex())
+		for j, t := range infos {
+			fmt.Printf("  %24s", t.format(bals[j][i]))
+		}
+		fmt.Println()
+	}
+}
+
+// erc20ABI 取自 abigen 生成的绑定，打包 / 解码都用它
+var erc20ABI = func() *abi.ABI {
+	a, err := token.Erc20MetaData.GetAbi()
+	if err != nil {
+		panic(err)
+	}
+	return a
+}()
+
+// tokenInfo 是一次读出的代币元信息；Err 非空表示不是可用的 ERC-20（至少 decimals 读取失败）
+type tokenInfo struct {
+	Address  common.Address
+	Name     string
+	Symbol   string
+	Decimals uint8
+	Err      error
+}
+
+func (t tokenInfo) label() string {
+	if t.Err != nil || t.Symbol == "" {
+		return shortHex(t.Address.Hex())
+	}
+	return t.Symbol
+}
+
+// format 按代币精度精确显示余额；元信息不可用或余额读取失败时显示占位
+func (t tokenInfo) format(bal *big.Int) string {
+	switch {
+	case bal == nil:
+		return "-"
+	case t.Err != nil:
+		return bal.String() + " (raw)"
+	}
+	return units.Format(bal, int(t.Decimals))
+}
+
+func newMulticall(client *ethclient.Client) *multicall.Client {
+	mc := multicall.New(client)
+	switch v := os.Getenv("MULTICALL"); {
+	case v == "off":
+		mc.Address = common.Address{}
+	case common.IsHexAddress(v):
+		mc.Address = common.HexToAddress(v)
+	case v != "":
+		log.Fatalf("[ERR] invalid MULTICALL: %s (want address | off)", v)
+	}
+	if v := os.Getenv("BATCH"); v != "" {
+		if _, err := fmt.Sscan(v, &mc.BatchSize); err != nil || mc.BatchSize < 1 {
+			log.Fatalf("[ERR] invalid BATCH: %s", v)
+		}
+	}
+	return mc
+}
+
+// fetchTokenInfo 每个代币打包 name / symbol / decimals 三个调用
+func fetchTokenInfo(ctx context.Context, mc *multicall.Client, tokens []common.Address) ([]tokenInfo, error) {
+	methods := []string{"name", "symbol", "decimals"}
+	calls := make([]multicall.Call, 0, len(tokens)*len(methods))
+	for _, t := range tokens {
+		for _, m := range methods {
+			data, err := erc20ABI.Pack(m)
+			if err != nil {
+				return nil, err
+			}
+			calls = append(calls, multicall.Call{Target: t, Data: data})
+		}
+	}
+	res, err := mc.Do(ctx, calls, nil)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]tokenInfo, len(tokens))
+	for i, t := range tokens {
+		r := res[i*len(methods):]
+		out[i] = tokenInfo{Address: t, Name: decodeString("name", r[0]), Symbol: decodeString("symbol", r[1])}
+		if r[2].Err != nil {
+			out[i].Err = fmt.Errorf("decimals(): %w", r[2].Err)
+			continue
+		}
+		v, err := erc20ABI.Unpack("decimals", r[2].ReturnData)
+		if err != nil {
+			out[i].Err = fmt.Errorf("decimals(): %w", err)
+			continue
+		}
+		out[i].Decimals = v[0].(uint8)
+	}
+	return out, nil
+}
+
+// fetchBalances 返回 [代币][持有人] 的余额；读取失败的格子为 nil
+func fetchBalances(ctx context.Context, mc *multicall.Client, tokens, holders []common.Address) ([][]*big.Int, error) {
+	calls := make([]multicall.Call, 0, len(tokens)*len(holders))
+	for _, t := range tokens {
+		for _, h := range holders {
+			data, err := erc20ABI.Pack("balanceOf", h)
+			if err != nil {
+				return nil, err
+			}
+			calls = append(calls, multicall.Call{Target: t, Data: data})
+		}
+	}
+	res, err := mc.Do(ctx, calls, nil)
+	if err != nil {
+		return nil, err
+	}
+	out := make([][]*big.Int, len(tokens))
+	for i := range tokens {
+		out[i] = make([]*big.Int, len(holders))
+		for j := range holders {
+			r := res[i*len(holders)+j]
+			if r.Err != nil {
+				continue
+			}
+			if v, err := erc20ABI.Unpack("balanceOf", r.ReturnData); err == nil {
+				out[i][j] = v[0].(*big.Int)
+			}
+		}
+	}
+	return out, nil
+}
+
+// decodeString 解码 name() / symbol()；兼容早期返回 bytes32 的代币（如 MKR），失败时返回空串
+func decodeString(method string, r multicall.Result) string {
+	if r.Err != nil {
+		return ""
+	}
+	if v, err := erc20ABI.Unpack(method, r.ReturnData); err == nil {
+		return v[0].(string)
+	}
+	if len(r.ReturnData) == 32 {
+		return string(bytes.TrimRight(r.ReturnData, "\x00"))
+	}
+	return ""
+}
+
+func parseAddressList(env string) []common.Address {
+	var out []common.Address
+	for _, s := range strings.Split(os.Getenv(env), ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !common.IsHexAddress(s) {
+			log.Fatalf("[ERR] %s: invalid address %q", env, s)
+		}
+		out = append(out, common.HexToAddress(s))
+	}
+	return out
+}
+
+func mustOK(tag string, err error) {
+	if err != nil {
+		log.Fatalf("[ERR] %s: %v", tag, err)
+	}
+}
+
+func getenv(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"example.com/ethclient-demo/pkg/units"
+)
+
+// runPortfolio 按代币列表（Uniswap token-list 格式）查询一个或多个持有人的全部代币余额：
+//
+//	go run ./08-token-balance-query portfolio 08-token-balance-query/tokens.sepolia.json 0x2583... [0x7099...]
+//
+// 列表里只取与当前链 chainId 相同的代币。name / symbol / decimals 以链上为准，读过的缓存在 TOKEN_CACHE
+// （默认 .token-cache.json，按 chainId 分组），下次不再查询；REFRESH=1 忽略缓存重新读取。
+// 其余环境变量：RPC_URL、SKIP_ZERO=1（不显示零余额）、MULTICALL / BATCH（同 multi）。
+// 余额按代币精度精确显示。“每个持有人的合计”指该持有人在每个代币上的持仓：不同代币单位不同、没有价格无法相加，
+// 所以每个持有人一节逐个代币列出精确余额，末尾只给出持有非零余额的代币数；多个持有人时再按代币汇总全部持有人的合计。
+func runPortfolio(args []string) {
+	if len(args) < 2 {
+		log.Fatal("usage: go run ./08-token-balance-query portfolio <tokenlist.json> <holder...>")
+	}
+	list, err := loadTokenList(args[0])
+	mustOK("load token list", err)
+	var holders []common.Address
+	for _, s := range args[1:] {
+		if !common.IsHexAddress(s) {
+			log.Fatalf("[ERR] invalid holder address: %s", s)
+		}
+		holders = append(holders, common.HexToAddress(s))
+	}
+	skipZero := os.Getenv("SKIP_ZERO") == "1"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	client, err := ethclient.DialContext(ctx, getenv("RPC_URL", defaultRPC))
+	mustOK("ethclient.Dial", err)
+	defer client.Close()
+	chainID, err := client.ChainID(ctx)
+	mustOK("chain id", err)
+
+	entries := list.forChain(chainID.Uint64())
+	fmt.Printf("[Portfolio] list=%q chainId=%s tokens=%d (of %d) holders=%d\n",
+		list.Name, chainID, len(entries), len(list.Tokens), len(holders))
+	if len(entries) == 0 {
+		return
+	}
+
+	// 1) 代币元信息：先查缓存，缺的一次 multicall 补齐后写回
+	cache, err := loadTokenCache(getenv("TOKEN_CACHE", ".token-cache.json"), chainID.Uint64())
+	mustOK("load token cache", err)
+	if os.Getenv("REFRESH") == "1" {
+		cache.Tokens = map[common.Address]cachedToken{}
+	}
+	var missing []common.Address
+	for _, e := range entries {
+		if _, ok := cache.Tokens[e.Address]; !ok {
+			missing = append(missing, e.Address)
+		}
+	}
+	mc := newMulticall(client)
+	if len(missing) > 0 {
+		infos, err := fetchTokenInfo(ctx, mc, missing)
+		mustOK("fetch token info", err)
+		for _, t := range infos {
+			if t.Err != nil {
+				log.Printf("[WARN] skip token %s: %v", t.Address.Hex(), t.Err)
+				continue
+			}
+			cache.Tokens[t.Address] = cachedToken{Name: t.Name, Symbol: t.Symbol, Decimals: t.Decimals}
+		}
+		mustOK("save token cache", cache.save())
+	}
+	fmt.Printf("  metadata: cached=%d fetched=%d cache=%s\n", len(entries)-len(missing), len(missing), cache.path)
+
+	var tokens []common.Address
+	var metas []cachedToken
+	for _, e := range entries {
+		m, ok := cache.Tokens[e.Address]
+		if !ok {
+			continue
+		}
+		if m.Decimals != e.Decimals {
+			log.Printf("[WARN] %s (%s): token list says decimals=%d, chain says %d; using chain",
+				e.Symbol, e.Address.Hex(), e.Decimals, m.Decimals)
+		}
+		if m.Symbol == "" {
+			m.Symbol = e.Symbol
+		}
+		tokens = append(tokens, e.Address)
+		metas = append(metas, m)
+	}
+	if len(tokens) == 0 {
+		return
+	}
+
+	// 2) 余额：代币 × 持有人一次批量读取
+	bals, err := fetchBalances(ctx, mc, tokens, holders)
+	mustOK("fetch balances", err)
+
+	totals := make([]*big.Int, len(tokens))
+	for i := range totals {
+		totals[i] = new(big.Int)
+	}
+	for j, h := range holders {
+		fmt.Printf("\n[Holder] %s\n", h.Hex())
+		held := 0
+		for i, m := range metas {
+			bal := bals[i][j]
+			if bal == nil {
+				fmt.Printf("  %-10s %32s  %s\n", m.Symbol, "<balanceOf failed>", shortHex(tokens[i].Hex()))
+				continue
+			}
+			totals[i].Add(totals[i], bal)
+			if bal.Sign() != 0 {
+				held++
+			} else if skipZero {
+				continue
+			}
+			fmt.Printf("  %-10s %32s  %s\n", m.Symbol, units.Format(bal, int(m.Decimals)), shortHex(tokens[i].Hex()))
+		}
+		fmt.Printf("  holding %d of %d tokens\n", held, len(tokens))
+	}
+
+	if len(holders) > 1 {
+		fmt.Printf("\n[Total] %d holders\n", len(holders))
+		for i, m := range metas {
+			if skipZero && totals[i].Sign() == 0 {
+				continue
+			}
+			fmt.Printf("  %-10s %32s  %s\n", m.Symbol, units.Format(totals[i], int(m.Decimals)), shortHex(tokens[i].Hex()))
+		}
+	}
+}
+
+// tokenList 是 Uniswap token-list（https://tokenlists.org）中本程序用到的字段
+type tokenList struct {
+	Name   string       `json:"name"`
+	Tokens []tokenEntry `json:"tokens"`
+}
+
+type tokenEntry struct {
+	ChainID  uint64         `json:"chainId"`
+	Address  common.Address `json:"address"`
+	Name     string         `json:"name"`
+	Symbol   string         `json:"symbol"`
+	Decimals uint8          `json:"decimals"`
+}
+
+func loadTokenList(path string) (*tokenList, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var l tokenList
+	if err := json.Unmarshal(raw, &l); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &l, nil
+}
+
+// forChain 取出指定链的代币并按地址去重，保持列表顺序
+func (l *tokenList) forChain(chainID uint64) []tokenEntry {
+	seen := map[common.Address]bool{}
+	var out []tokenEntry
+	for _, t := range l.Tokens {
+		if t.ChainID != chainID || seen[t.Address] {
+			continue
+		}
+		seen[t.Address] = true
+		out = append(out, t)
+	}
+	return out
+}
+
+type cachedToken struct {
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+	Decimals uint8  `json:"decimals"`
+}
+
+// tokenCache 是磁盘上的元信息缓存：{"<chainId>": {"<address>": {...}}}；只读写当前链那一组，其他链原样保留
+type tokenCache struct {
+	path   string
+	key    string
+	all    map[string]map[common.Address]cachedToken
+	Tokens map[common.Address]cachedToken
+}
+
+func loadTokenCache(path string, chainID uint64) (*tokenCache, error) {
+	c := &tokenCache{path: path, key: fmt.Sprint(chainID), all: map[string]map[common.Address]cachedToken{}}
+	raw, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(raw, &c.all); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+	}
+	c.Tokens = c.all[c.key]
+	if c.Tokens == nil {
+		c.Tokens = map[common.Address]cachedToken{}
+	}
+	return c, nil
+}
+
+// save 先写临时文件再 rename，避免进程中途退出留下半截文件
+func (c *tokenCache) save() error {
+	c.all[c.key] = c.Tokens
+	raw, err := json.MarshalIndent(c.all, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".token-cache-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(raw, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	usdc = "0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238"
+	link = "0x779877A7B0D9E8603169DdbD7836e478b4624789"
+	weth = "0xfFf9976782d46CC05630D1f6eBAb18b2324d6B14"
+)
+
+func TestForChain(t *testing.T) {
+	// 地址大小写不同也算同一个代币：common.Address 反序列化时已经归一
+	raw := `{"name":"t","tokens":[
+		{"chainId":11155111,"address":"` + link + `","symbol":"LINK","decimals":18},
+		{"chainId":1,"address":"` + usdc + `","symbol":"USDC-mainnet","decimals":6},
+		{"chainId":11155111,"address":"` + usdc + `","symbol":"USDC","decimals":6},
+		{"chainId":11155111,"address":"0x779877a7b0d9e8603169ddbd7836e478b4624789","symbol":"LINK-dup","decimals":18},
+		{"chainId":11155111,"address":"` + weth + `","symbol":"WETH","decimals":18}
+	]}`
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	list, err := loadTokenList(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		chainID uint64
+		want    []string // 按列表顺序的 symbol
+	}{
+		{11155111, []string{"LINK", "USDC", "WETH"}}, // 去重保留第一次出现
+		{1, []string{"USDC-mainnet"}},                // 同一地址在不同链上互不影响
+		{10, nil},
+	} {
+		var got []string
+		for _, e := range list.forChain(c.chainID) {
+			got = append(got, e.Symbol)
+		}
+		if !slices.Equal(got, c.want) {
+			t.Errorf("chain %d: %v, want %v", c.chainID, got, c.want)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(`{"tokens":[`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadTokenList(path); err == nil {
+		t.Error("truncated token list accepted")
+	}
+}
+
+func TestTokenCacheKeepsOtherChains(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	mainnet := map[common.Address]cachedToken{common.HexToAddress(usdc): {Name: "USD Coin", Symbol: "USDC", Decimals: 6}}
+	raw, err := json.Marshal(map[string]map[common.Address]cachedToken{"1": mainnet})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 读 sepolia 这组（文件里还没有），写入一个代币后保存
+	c, err := loadTokenCache(path, 11155111)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Tokens) != 0 {
+		t.Fatalf("sepolia cache = %v, want empty", c.Tokens)
+	}
+	wethMeta := cachedToken{Name: "Wrapped Ether", Symbol: "WETH", Decimals: 18}
+	c.Tokens[common.HexToAddress(weth)] = wethMeta
+	if err := c.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 两条链分别重新读取：新写入的在，主网那组原样保留
+	c, err = loadTokenCache(path, 11155111)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Tokens[common.HexToAddress(weth)]; len(c.Tokens) != 1 || got != wethMeta {
+		t.Errorf("sepolia after save = %v", c.Tokens)
+	}
+	c, err = loadTokenCache(path, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Tokens[common.HexToAddress(usdc)]; len(c.Tokens) != 1 || got != mainnet[common.HexToAddress(usdc)] {
+		t.Errorf("mainnet after save = %v", c.Tokens)
+	}
+
+	// 没有临时文件残留
+	if left, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".token-cache-*")); len(left) != 0 {
+		t.Errorf("temp files left behind: %v", left)
+	}
+
+	// 缓存文件不存在时从空开始，save 会新建
+	missing := filepath.Join(t.TempDir(), "none.json")
+	c, err = loadTokenCache(missing, 1)
+	if err != nil || len(c.Tokens) != 0 {
+		t.Fatalf("missing cache: %v, %v", c.Tokens, err)
+	}
+	if err := c.save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(missing); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// chainEventKind 区分“区块被撤销”与“区块被应用”两类事件
+type chainEventKind int
+
+const (
+	eventApplied chainEventKind = iota
+	eventReverted
+)
+
+func (k chainEventKind) String() string {
+	if k == eventReverted {
+		return "reverted"
+	}
+	return "applied"
+}
+
+// chainEvent 是 follower 对外输出的事件；下游按顺序消费即可回滚/重放自己的状态
+type chainEvent struct {
+	Kind   chainEventKind
+	Header *types.Header
+}
+
+// headerByHash 是 follower 回溯父块时唯一需要的能力
+type headerByHash interface {
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+}
+
+// errReorgTooDeep：新链与窗口内任何区块都接不上（分叉点早于窗口起点）
+var errReorgTooDeep = errors.New("reorg deeper than tracked window")
+
+// reorgFollower 维护最近 depth 个规范链区块头（按高度升序），
+// 每来一个新头就检查 ParentHash 是否接得上，接不上则沿父哈希回溯到共同祖先，
+// 先按高度降序输出被撤销的旧块，再按高度升序输出新链上的区块。
+type reorgFollower struct {
+	client headerByHash
+	depth  int
+	window []*types.Header
+	hashes []common.Hash       // 与 window 一一对应，避免反复计算 Keccak
+	index  map[common.Hash]int // hash -> window 下标
+}
+
+func newReorgFollower(c headerByHash, depth int) *reorgFollower {
+	if depth < 2 {
+		depth = 2
+	}
+	return &reorgFollower{client: c, depth: depth, index: make(map[common.Hash]int)}
+}
+
+// Process 处理一个新区块头，返回需要按顺序应用的事件。
+// 回溯深度超过窗口时返回 errReorgTooDeep，此时窗口已重置为只包含该新头，
+// 返回的事件里也只有该新头的 applied，调用方应当记录告警（下游需要自行全量对账）。
+func (f *reorgFollower) Process(ctx context.Context, h *types.Header) ([]chainEvent, error) {
+	hash := h.Hash()
+	if _, seen := f.index[hash]; seen {
+		return nil, nil // 重复推送（例如重连后补发），忽略
+	}
+	if len(f.window) == 0 {
+		f.push(h)
+		return []chainEvent{{Kind: eventApplied, Header: h}}, nil
+	}
+
+	// 快路径：直接接在当前链尖后面
+	if h.ParentHash == f.hashes[len(f.hashes)-1] {
+		f.push(h)
+		return []chainEvent{{Kind: eventApplied, Header: h}}, nil
+	}
+
+	// 慢路径：沿父哈希向前找，直到遇到窗口里的区块（共同祖先）
+	branch := []*types.Header{h}
+	oldest := f.window[0].Number
+	cur := h
+	ancestor := -1
+	for {
+		if i, ok := f.index[cur.ParentHash]; ok {
+			ancestor = i
+			break
+		}
+		if cur.Number.Cmp(oldest) <= 0 {
+			break // 已经越过窗口起点还没接上
+		}
+		parent, err := f.client.HeaderByHash(ctx, cur.ParentHash)
+		if err != nil {
+			return nil, fmt.Errorf("header by hash %s: %w", cur.ParentHash.Hex(), err)
+		}
+		branch = append(branch, parent)
+		cur = parent
+	}
+
+	if ancestor < 0 {
+		f.reset()
+		f.push(h)
+		return []chainEvent{{Kind: eventApplied, Header: h}}, errReorgTooDeep
+	}
+
+	// 1) 撤销祖先之后的旧块（从链尖往回）
+	var events []chainEvent
+	for i := len(f.window) - 1; i > ancestor; i-- {
+		events = append(events, chainEvent{Kind: eventReverted, Header: f.window[i]})
+		delete(f.index, f.hashes[i])
+	}
+	f.window = f.window[:ancestor+1]
+	f.hashes = f.hashes[:ancestor+1]
+
+	// 2) 应用新分支（branch 是从新到旧收集的，反过来依次追加）
+	for i := len(branch) - 1; i >= 0; i-- {
+		f.push(branch[i])
+		events = append(events, chainEvent{Kind: eventApplied, Header: branch[i]})
+	}
+	return events, nil
+}
+
+// Tip 返回当前规范链尖；窗口为空时返回 nil
+func (f *reorgFollower) Tip() *types.Header {
+	if len(f.window) == 0 {
+		return nil
+	}
+	return f.window[len(f.window)-1]
+}
+
+func (f *reorgFollower) push(h *types.Header) {
+	f.window = append(f.window, h)
+	f.hashes = append(f.hashes, h.Hash())
+	if drop := len(f.window) - f.depth; drop > 0 {
+		for _, old := range f.hashes[:drop] {
+			delete(f.index, old)
+		}
+		f.window = append([]*types.Header(nil), f.window[drop:]...)
+		f.hashes = append([]common.Hash(nil), f.hashes[drop:]...)
+		for i, hash := range f.hashes {
+			f.index[hash] = i
+		}
+		return
+	}
+	f.index[f.hashes[len(f.hashes)-1]] = len(f.hashes) - 1
+}
+
+func (f *reorgFollower) reset() {
+	f.window = nil
+	f.hashes = nil
+	f.index = make(map[common.Hash]int)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// headerStore 是 HeaderByHash 的内存替身，记下所有分支上的区块头
+type headerStore map[common.Hash]*types.Header
+
+func (s headerStore) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	if h, ok := s[hash]; ok {
+		return h, nil
+	}
+	return nil, fmt.Errorf("header %s not found", hash.Hex())
+}
+
+// extend 从 parent 往后接 k 个区块；fork 写进 Extra，让不同分支同高度的区块哈希不同
+func (s headerStore) extend(parent *types.Header, k int, fork string) []*types.Header {
+	var out []*types.Header
+	for i := 0; i < k; i++ {
+		h := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number, common.Big1),
+			Difficulty: new(big.Int),
+			Extra:      []byte(fork),
+		}
+		s[h.Hash()] = h
+		out = append(out, h)
+		parent = h
+	}
+	return out
+}
+
+// describe 把事件序列写成 "reverted a#10 applied b#8 ..." 便于比较
+func describe(events []chainEvent) string {
+	parts := make([]string, len(events))
+	for i, ev := range events {
+		parts[i] = fmt.Sprintf("%s %s#%s", ev.Kind, ev.Header.Extra, ev.Header.Number)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestReorgFollower(t *testing.T) {
+	store := headerStore{}
+	genesis := &types.Header{Number: new(big.Int), Difficulty: new(big.Int), Extra: []byte("a")}
+	store[genesis.Hash()] = genesis
+	chainA := append([]*types.Header{genesis}, store.extend(genesis, 10, "a")...) // a#0..a#10
+	chainB := store.extend(chainA[7], 4, "b")                                     // b#8..b#11，从 a#7 分叉
+
+	feed := func(t *testing.T, f *reorgFollower, hs []*types.Header) {
+		t.Helper()
+		for _, h := range hs {
+			events, err := f.Process(context.Background(), h)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(events) != 1 || events[0].Kind != eventApplied || events[0].Header != h {
+				t.Fatalf("extend with #%s: %s", h.Number, describe(events))
+			}
+		}
+	}
+
+	for _, c := range []struct {
+		name string
+		head *types.Header
+		want string
+	}{
+		// 同高度、父哈希不同：撤销 a#10..a#8（降序），应用 b#8..b#10（升序）
+		{"same height", chainB[2], "reverted a#10 reverted a#9 reverted a#8 applied b#8 applied b#9 applied b#10"},
+		// 新链更长：同样先撤销再应用，最后是新链尖
+		{"longer fork", chainB[3], "reverted a#10 reverted a#9 reverted a#8 applied b#8 applied b#9 applied b#10 applied b#11"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			f := newReorgFollower(store, 16)
+			feed(t, f, chainA[1:])
+			events, err := f.Process(context.Background(), c.head)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := describe(events); got != c.want {
+				t.Errorf("events:\n got %s\nwant %s", got, c.want)
+			}
+			if f.Tip() != c.head {
+				t.Errorf("tip = #%s, want #%s", f.Tip().Number, c.head.Number)
+			}
+			// 重组后旧链尖的重复推送被忽略，新链可以继续接上
+			if events, _ := f.Process(context.Background(), c.head); len(events) != 0 {
+				t.Errorf("duplicate head produced %s", describe(events))
+			}
+			feed(t, f, store.extend(c.head, 1, "b"))
+		})
+	}
+
+	t.Run("too deep", func(t *testing.T) {
+		// 窗口只有 3 个块（a#8..a#10），分叉点 a#7 在窗口之外
+		f := newReorgFollower(store, 3)
+		feed(t, f, chainA[1:])
+		events, err := f.Process(context.Background(), chainB[2])
+		if !errors.Is(err, errReorgTooDeep) {
+			t.Fatalf("err = %v, want errReorgTooDeep", err)
+		}
+		if got := describe(events); got != "applied b#10" {
+			t.Errorf("events = %s, want only the new head", got)
+		}
+		if f.Tip() != chainB[2] {
+			t.Errorf("window not reset to the new head")
+		}
+	})
+}
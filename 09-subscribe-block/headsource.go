@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// headSource 产出高度连续的新区块头流；websocket 订阅与 HTTP 轮询只是获取方式不同，
+// 下游（reorgFollower + 打印）完全不用关心是哪一种。
+type headSource interface {
+	Run(ctx context.Context, out chan<- *types.Header) error
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+	BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error)
+	Close()
+}
+
+// newHeadSource 按 URL scheme 自动选择实现：ws/wss 走订阅，http/https 走 HeaderByNumber(nil) 轮询
+func newHeadSource(ctx context.Context, rawURL string, pollInterval time.Duration) (headSource, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse rpc url: %w", err)
+	}
+	switch u.Scheme {
+	case "ws", "wss":
+		heads := newResilientHeads(func(ctx context.Context) (*ethclient.Client, error) {
+			return ethclient.DialContext(ctx, rawURL)
+		})
+		if _, err := heads.Connect(ctx); err != nil {
+			return nil, err
+		}
+		return heads, nil
+	case "http", "https":
+		client, err := ethclient.DialContext(ctx, rawURL)
+		if err != nil {
+			return nil, err
+		}
+		return &pollHeads{headPoller: client, interval: pollInterval, maxFailures: 10}, nil
+	default:
+		return nil, fmt.Errorf("unsupported rpc scheme %q (want ws/wss/http/https)", u.Scheme)
+	}
+}
+
+// headPoller 是轮询需要的节点能力；*ethclient.Client 满足，测试时可换成假节点
+type headPoller interface {
+	blockByNumber
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+	BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error)
+	Close()
+}
+
+// pollHeads 每隔 interval 查询一次最新区块头；链尖哈希变了才输出，跳号时同样补齐
+type pollHeads struct {
+	headPoller
+	gapFiller
+	interval    time.Duration
+	maxFailures int // 连续失败这么多次后放弃并返回最后一个错误；<= 0 表示一直重试
+}
+
+// Run 持续轮询直到 ctx 取消；单次查询失败只打印告警，下个周期继续，连续失败 maxFailures 次才返回错误
+func (p *pollHeads) Run(ctx context.Context, out chan<- *types.Header) error {
+	log.Printf("[OK ] polling new heads every %v", p.interval)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	failures := 0
+	for {
+		if err := p.poll(ctx, out); err != nil && ctx.Err() == nil {
+			failures++
+			if p.maxFailures > 0 && failures >= p.maxFailures {
+				return fmt.Errorf("poll head failed %d times in a row: %w", failures, err)
+			}
+			log.Printf("[WARN] poll head failed: %v", err)
+		} else {
+			failures = 0
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *pollHeads) poll(ctx context.Context, out chan<- *types.Header) error {
+	hctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	head, err := p.HeaderByNumber(hctx, nil)
+	cancel()
+	if err != nil {
+		return err
+	}
+	return p.emit(ctx, p.headPoller, head, out) // 链尖没变时 emit 直接忽略
+}
+
+// blockByNumber 是补块时唯一需要的能力
+type blockByNumber interface {
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+}
+
+// gapFiller 记录已输出的最高高度，输出新头之前先补齐中间缺失的区块。
+// 同高度换了哈希（重组）的头照常输出，由 reorgFollower 负责判断；
+// 已经输出过的同一个头（重连后链尖没变、补块后订阅又推送了同一块）直接忽略。
+type gapFiller struct {
+	lastMu sync.Mutex
+	last   *big.Int               // 已输出的最高高度；nil 表示还没输出过
+	sent   map[uint64]common.Hash // 最近 sentWindow 个高度上输出过的哈希，用于去重
+}
+
+// sentWindow 是去重记忆的高度范围，足够覆盖一次重连期间的补块
+const sentWindow = 256
+
+// emit 输出 h；若 h 与上一次输出的高度之间有空洞，先按高度顺序补齐
+func (g *gapFiller) emit(ctx context.Context, client blockByNumber, h *types.Header, out chan<- *types.Header) error {
+	if g.alreadySent(h) {
+		return nil
+	}
+	if last := g.lastSeen(); last != nil {
+		for n := new(big.Int).Add(last, common.Big1); n.Cmp(h.Number) < 0; n.Add(n, common.Big1) {
+			bctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+			b, err := client.BlockByNumber(bctx, n)
+			cancel()
+			if err != nil {
+				return err
+			}
+			log.Printf("[OK ] backfilled #%s", n)
+			if err := g.send(ctx, b.Header(), out); err != nil {
+				return err
+			}
+		}
+	}
+	return g.send(ctx, h, out)
+}
+
+func (g *gapFiller) send(ctx context.Context, h *types.Header, out chan<- *types.Header) error {
+	select {
+	case out <- h:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	g.lastMu.Lock()
+	defer g.lastMu.Unlock()
+	if g.last == nil || h.Number.Cmp(g.last) > 0 {
+		g.last = new(big.Int).Set(h.Number)
+	}
+	if g.sent == nil {
+		g.sent = make(map[uint64]common.Hash)
+	}
+	n := h.Number.Uint64()
+	g.sent[n] = h.Hash()
+	if n >= sentWindow {
+		delete(g.sent, n-sentWindow)
+	}
+	return nil
+}
+
+func (g *gapFiller) alreadySent(h *types.Header) bool {
+	g.lastMu.Lock()
+	defer g.lastMu.Unlock()
+	hash, ok := g.sent[h.Number.Uint64()]
+	return ok && hash == h.Hash()
+}
+
+// lastSeen 返回最近输出的最高高度（拷贝）
+func (g *gapFiller) lastSeen() *big.Int {
+	g.lastMu.Lock()
+	defer g.lastMu.Unlock()
+	if g.last == nil {
+		return nil
+	}
+	return new(big.Int).Set(g.last)
+}
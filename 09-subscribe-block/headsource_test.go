@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakePoller 按脚本依次返回链尖：tips 里的高度对应 chain.headers 下标，-1 表示这次查询失败
+type fakePoller struct {
+	chain    *fakeChain
+	tips     []int
+	backfill []uint64 // 记录被补块请求的高度
+}
+
+var errPoll = errors.New("502 Bad Gateway")
+
+func (f *fakePoller) HeaderByNumber(ctx context.Context, n *big.Int) (*types.Header, error) {
+	if len(f.tips) == 0 {
+		return nil, errPoll
+	}
+	tip := f.tips[0]
+	f.tips = f.tips[1:]
+	if tip < 0 {
+		return nil, errPoll
+	}
+	return f.chain.headers[tip], nil
+}
+
+func (f *fakePoller) BlockByNumber(ctx context.Context, n *big.Int) (*types.Block, error) {
+	f.backfill = append(f.backfill, n.Uint64())
+	if n.Uint64() >= uint64(len(f.chain.headers)) {
+		return nil, ethereum.NotFound
+	}
+	return types.NewBlockWithHeader(f.chain.headers[n.Uint64()]), nil
+}
+
+func (f *fakePoller) HeaderByHash(context.Context, common.Hash) (*types.Header, error) {
+	return nil, ethereum.NotFound
+}
+
+func (f *fakePoller) BlockByHash(context.Context, common.Hash) (*types.Block, error) {
+	return nil, ethereum.NotFound
+}
+
+func (f *fakePoller) Close() {}
+
+func TestPollHeadsGapAndDedupe(t *testing.T) {
+	chain := newFakeChain()
+	chain.mine(8)
+	// 链尖：#1，没变的 #1，跳到 #4，没变，#5，查询失败，跳到 #8
+	f := &fakePoller{chain: chain, tips: []int{1, 1, 4, 4, 5, -1, 8}}
+	p := &pollHeads{headPoller: f, interval: time.Millisecond}
+
+	out := make(chan *types.Header, 32)
+	for range len(f.tips) {
+		if err := p.poll(context.Background(), out); err != nil && !errors.Is(err, errPoll) {
+			t.Fatal(err)
+		}
+	}
+	close(out)
+	var got []uint64
+	for h := range out {
+		if h.Hash() != chain.headers[h.Number.Uint64()].Hash() {
+			t.Errorf("head #%s has wrong hash", h.Number)
+		}
+		got = append(got, h.Number.Uint64())
+	}
+	if want := []uint64{1, 2, 3, 4, 5, 6, 7, 8}; !slices.Equal(got, want) {
+		t.Fatalf("heads = %v, want %v", got, want)
+	}
+	// 只补中间缺失的高度，不重复拉取已输出的块
+	if wantFill := []uint64{2, 3, 6, 7}; !slices.Equal(f.backfill, wantFill) {
+		t.Errorf("backfilled %v, want %v", f.backfill, wantFill)
+	}
+}
+
+func TestPollHeadsGivesUp(t *testing.T) {
+	chain := newFakeChain()
+	chain.mine(2)
+	// 成功一次后持续失败：失败计数在成功时清零，连续失败达到上限才返回错误
+	f := &fakePoller{chain: chain, tips: []int{1, -1, -1, 2, -1, -1, -1}}
+	p := &pollHeads{headPoller: f, interval: time.Millisecond, maxFailures: 3}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	out := make(chan *types.Header, 8)
+	err := p.Run(ctx, out)
+	if !errors.Is(err, errPoll) {
+		t.Fatalf("Run = %v, want %v", err, errPoll)
+	}
+	if len(f.tips) != 0 || len(out) != 2 {
+		t.Errorf("remaining tips = %v, heads = %d, want 0 / 2", f.tips, len(out))
+	}
+}
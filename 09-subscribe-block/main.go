@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -10,68 +11,113 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 )
 
+// reorgWindow 是 follower 记住的最近规范区块数；重组深度超过它就无法精确回滚
+const reorgWindow = 64
+
 func main() {
-	// 1) 连接 WebSocket 节点（示例使用 Sepolia；替换为你的实际 WS URL）
-	wsURL := "wss://eth-sepolia.g.alchemy.com/v2/xxx"
-	client, err := ethclient.Dial(wsURL)
+	// 1) 连接节点（示例使用 Sepolia；替换为你的实际 URL）
+	//    wss:// 走 SubscribeNewHead 订阅；只有 https:// 时自动退化为 HeaderByNumber(nil) 轮询
+	rpcURL := getenv("RPC_URL", "wss://eth-sepolia.g.alchemy.com/v2/xxx")
+	pollInterval, err := time.ParseDuration(getenv("POLL_INTERVAL", "4s"))
 	if err != nil {
-		log.Fatalf("[ERR] ethclient.Dial: %v", err)
+		log.Fatalf("[ERR] invalid POLL_INTERVAL: %v", err)
+	}
+	if pollInterval <= 0 { // time.NewTicker 不接受非正的间隔
+		log.Fatalf("[ERR] invalid POLL_INTERVAL: must be positive, got %s", pollInterval)
 	}
-	defer client.Close()
-	log.Printf("[OK ] connected: %s", wsURL)
 
-	// 2) 订阅新区块头
-	headers := make(chan *types.Header, 16)
-	sub, err := client.SubscribeNewHead(context.Background(), headers)
+	// Ctrl+C 优雅退出
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	heads, err := newHeadSource(ctx, rpcURL, pollInterval)
 	if err != nil {
-		log.Fatalf("[ERR] SubscribeNewHead: %v", err)
+		log.Fatalf("[ERR] ethclient.Dial: %v", err)
 	}
-	log.Println("[OK ] subscribed to new heads")
+	defer heads.Close()
+	log.Printf("[OK ] connected: %s", rpcURL)
 
-	// Ctrl+C 优雅退出
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	// 2) 获取新区块头流（订阅断线自动重连，轮询/重连时自动补齐缺失区块）
+	headers := make(chan *types.Header, 16)
+	runErr := make(chan error, 1)
+	go func() { runErr <- heads.Run(ctx, headers) }()
+
+	// 维护最近 reorgWindow 个规范区块，用于识别重组
+	follower := newReorgFollower(heads, reorgWindow)
 
 	for {
 		select {
-		case <-quit:
+		case <-ctx.Done():
 			log.Println("[EXIT] received interrupt, bye")
 			return
 
-		case err := <-sub.Err():
-			log.Fatalf("[ERR] subscription error: %v", err)
+		case err := <-runErr:
+			// Run 只在 ctx 取消或无法恢复（如轮询持续失败）时返回
+			if ctx.Err() != nil {
+				log.Println("[EXIT] received interrupt, bye")
+				return
+			}
+			log.Fatalf("[ERR] head source stopped: %v", err)
 
 		case header := <-headers:
-			// 简要打印头部信息
-			printHeaderBrief(header)
-
-			// 3) 拉取完整区块（为避免阻塞，这里给个短超时）
-			ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
-			block, err := client.BlockByHash(ctx, header.Hash())
+			// 3) 交给 follower 校验 ParentHash，必要时回溯到共同祖先
+			pctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+			events, err := follower.Process(pctx, header)
 			cancel()
-			if err != nil {
-				log.Printf("[WARN] BlockByHash(%s) failed: %v", header.Hash().Hex(), err)
+			if errors.Is(err, errReorgTooDeep) {
+				log.Printf("[WARN] reorg deeper than %d blocks at #%s, window reset", reorgWindow, header.Number)
+			} else if err != nil {
+				log.Printf("[WARN] follow head #%s failed: %v", header.Number, err)
 				continue
 			}
 
-			// 4) 统一格式打印区块详情
-			printBlock(block)
+			for _, ev := range events {
+				handleChainEvent(heads, ev)
+			}
 		}
 	}
 }
 
+// handleChainEvent 打印一条链事件；applied 额外拉取完整区块
+func handleChainEvent(client headSource, ev chainEvent) {
+	if ev.Kind == eventReverted {
+		printReverted(ev.Header)
+		return
+	}
+
+	// 简要打印头部信息
+	printHeaderBrief(ev.Header)
+
+	// 拉取完整区块（为避免阻塞，这里给个短超时）
+	ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
+	block, err := client.BlockByHash(ctx, ev.Header.Hash())
+	cancel()
+	if err != nil {
+		log.Printf("[WARN] BlockByHash(%s) failed: %v", ev.Header.Hash().Hex(), err)
+		return
+	}
+
+	// 统一格式打印区块详情
+	printBlock(block)
+}
+
 // ======== 打印工具函数 ========
 
 func printHeaderBrief(h *types.Header) {
-	fmt.Printf("\n[NewHead]\n")
+	fmt.Printf("\n[Applied]\n")
 	fmt.Printf("  - number:     %s\n", h.Number.String())
 	fmt.Printf("  - hash:       %s (%s)\n", h.Hash().Hex(), shortHex(h.Hash().Hex()))
 	fmt.Printf("  - parent:     %s (%s)\n", h.ParentHash.Hex(), shortHex(h.ParentHash.Hex()))
 }
 
+func printReverted(h *types.Header) {
+	fmt.Printf("\n[Reverted]\n")
+	fmt.Printf("  - number:     %s\n", h.Number.String())
+	fmt.Printf("  - hash:       %s (%s)\n", h.Hash().Hex(), shortHex(h.Hash().Hex()))
+}
+
 func printBlock(b *types.Block) {
 	t := time.Unix(int64(b.Time()), 0)
 	fmt.Printf("[Block]\n")
@@ -93,3 +139,10 @@ func shortHex(h string) string {
 	}
 	return fmt.Sprintf("%s...%s", h[:8], h[len(h)-6:])
 }
+
+func getenv(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// resilientHeads 包装 SubscribeNewHead：订阅断开后按指数退避重连、重新订阅，
+// 并用 BlockByNumber 补齐断线期间（以及节点推送跳号时）漏掉的区块，保证输出的高度连续。
+// dial 由调用方注入，测试时可以换成本地 websocket 替身。
+type resilientHeads struct {
+	dial       func(ctx context.Context) (*ethclient.Client, error)
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	gapFiller
+
+	mu     sync.Mutex
+	client *ethclient.Client
+}
+
+func newResilientHeads(dial func(ctx context.Context) (*ethclient.Client, error)) *resilientHeads {
+	return &resilientHeads{dial: dial, minBackoff: time.Second, maxBackoff: 30 * time.Second}
+}
+
+// Connect 建立（或复用）当前连接
+func (r *resilientHeads) Connect(ctx context.Context) (*ethclient.Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.client != nil {
+		return r.client, nil
+	}
+	c, err := r.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.client = c
+	return c, nil
+}
+
+// Close 关闭当前连接
+func (r *resilientHeads) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.client != nil {
+		r.client.Close()
+		r.client = nil
+	}
+}
+
+// HeaderByHash 转发到当前连接，供 reorgFollower 回溯父块
+func (r *resilientHeads) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	c, err := r.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.HeaderByHash(ctx, hash)
+}
+
+// BlockByHash 转发到当前连接，供打印完整区块
+func (r *resilientHeads) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	c, err := r.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.BlockByHash(ctx, hash)
+}
+
+// Run 持续把新区块头写入 out，直到 ctx 取消。
+// 订阅出错时不退出：关闭旧连接 → 等待 backoff（1s 起步、翻倍、封顶 30s）→ 重连重订阅 → 补块。
+func (r *resilientHeads) Run(ctx context.Context, out chan<- *types.Header) error {
+	backoff := r.minBackoff
+	for {
+		client, err := r.Connect(ctx)
+		if err == nil {
+			var subscribed bool
+			subscribed, err = r.follow(ctx, client, out)
+			if subscribed {
+				backoff = r.minBackoff // 成功订阅过，退避从头算
+			}
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		log.Printf("[WARN] head subscription lost: %v; reconnecting in %v", err, backoff)
+		r.Close()
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+		if backoff > r.maxBackoff {
+			backoff = r.maxBackoff
+		}
+	}
+}
+
+// follow 在一条连接上订阅并转发新区块头；返回值 subscribed 表示订阅是否成功建立过
+func (r *resilientHeads) follow(ctx context.Context, client *ethclient.Client, out chan<- *types.Header) (bool, error) {
+	headers := make(chan *types.Header, 16)
+	sub, err := client.SubscribeNewHead(ctx, headers)
+	if err != nil {
+		return false, err
+	}
+	defer sub.Unsubscribe()
+	log.Println("[OK ] subscribed to new heads")
+
+	// 重连场景：先对齐一次链尖，把断线期间漏掉的区块补上，不必等下一个新块推送
+	if last := r.lastSeen(); last != nil {
+		hctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+		head, err := client.HeaderByNumber(hctx, nil)
+		cancel()
+		if err != nil {
+			return true, err
+		}
+		if err := r.emit(ctx, client, head, out); err != nil {
+			return true, err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case err := <-sub.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			return true, err
+		case h := <-headers:
+			if err := r.emit(ctx, client, h, out); err != nil {
+				return true, err
+			}
+		}
+	}
+}
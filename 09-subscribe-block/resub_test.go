@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeChain 是最小的 eth 命名空间：eth_getBlockByNumber 与 eth_subscribe("newHeads")
+type fakeChain struct {
+	mu      sync.Mutex
+	headers []*types.Header
+	subs    map[rpc.ID]*rpc.Notifier
+}
+
+func newFakeChain() *fakeChain {
+	c := &fakeChain{subs: map[rpc.ID]*rpc.Notifier{}}
+	c.headers = append(c.headers, c.header(0, common.Hash{}))
+	return c
+}
+
+func (c *fakeChain) header(n uint64, parent common.Hash) *types.Header {
+	return &types.Header{
+		ParentHash:  parent,
+		UncleHash:   types.EmptyUncleHash,
+		Root:        types.EmptyRootHash,
+		TxHash:      types.EmptyTxsHash,
+		ReceiptHash: types.EmptyReceiptsHash,
+		Difficulty:  new(big.Int),
+		Number:      new(big.Int).SetUint64(n),
+		GasLimit:    30_000_000,
+		Time:        1_700_000_000 + n*12,
+	}
+}
+
+// mine 追加 k 个区块并推送给当前所有订阅者
+func (c *fakeChain) mine(k int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := 0; i < k; i++ {
+		last := c.headers[len(c.headers)-1]
+		h := c.header(last.Number.Uint64()+1, last.Hash())
+		c.headers = append(c.headers, h)
+		for id, n := range c.subs {
+			n.Notify(id, h)
+		}
+	}
+}
+
+func (c *fakeChain) subscribers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.subs)
+}
+
+func (c *fakeChain) GetBlockByNumber(n rpc.BlockNumber, full bool) (map[string]any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h := c.headers[len(c.headers)-1]
+	if n >= 0 {
+		if int(n) >= len(c.headers) {
+			return nil, nil // 节点对不存在的区块返回 null
+		}
+		h = c.headers[n]
+	}
+	raw, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	out["transactions"] = []any{}
+	out["uncles"] = []any{}
+	return out, nil
+}
+
+func (c *fakeChain) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	n, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := n.CreateSubscription()
+	c.mu.Lock()
+	c.subs[sub.ID] = n
+	c.mu.Unlock()
+	go func() {
+		<-sub.Err()
+		c.mu.Lock()
+		delete(c.subs, sub.ID)
+		c.mu.Unlock()
+	}()
+	return sub, nil
+}
+
+// connTracker 记下所有接受的 TCP 连接，测试里直接掐断来模拟节点断线
+type connTracker struct {
+	net.Listener
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+func (l *connTracker) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err == nil {
+		l.mu.Lock()
+		l.conns = append(l.conns, c)
+		l.mu.Unlock()
+	}
+	return c, err
+}
+
+func (l *connTracker) dropAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, c := range l.conns {
+		c.Close()
+	}
+	l.conns = nil
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestResubscribeBackfill(t *testing.T) {
+	chain := newFakeChain()
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", chain); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+	ts := httptest.NewUnstartedServer(srv.WebsocketHandler([]string{"*"}))
+	lis := &connTracker{Listener: ts.Listener}
+	ts.Listener = lis
+	ts.Start()
+	defer ts.Close()
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http")
+
+	// 每次拨号消耗一个令牌：断线期间不给令牌，保证漏掉的区块只能靠补块拿到
+	tokens := make(chan struct{}, 8)
+	tokens <- struct{}{}
+	dials := 0
+	heads := newResilientHeads(func(ctx context.Context) (*ethclient.Client, error) {
+		select {
+		case <-tokens:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		dials++
+		return ethclient.DialContext(ctx, wsURL)
+	})
+	heads.minBackoff, heads.maxBackoff = 10*time.Millisecond, 20*time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := make(chan *types.Header, 64)
+	done := make(chan error, 1)
+	go func() { done <- heads.Run(ctx, out) }()
+
+	var mu sync.Mutex
+	var got []*types.Header
+	go func() {
+		for h := range out {
+			mu.Lock()
+			got = append(got, h)
+			mu.Unlock()
+		}
+	}()
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(got)
+	}
+
+	waitFor(t, "first subscription", func() bool { return chain.subscribers() == 1 })
+	chain.mine(3) // #1..#3 经订阅推送
+	waitFor(t, "heads 1-3", func() bool { return count() == 3 })
+
+	// 1) 断线期间出了 5 个块，重连后应补齐 #4..#8
+	lis.dropAll()
+	waitFor(t, "server to drop subscription", func() bool { return chain.subscribers() == 0 })
+	chain.mine(5)
+	tokens <- struct{}{}
+	waitFor(t, "heads 4-8", func() bool { return count() == 8 })
+
+	// 2) 断线期间没有新块：重连后不能重复输出 #8。
+	// 新块可能在重新订阅与对齐链尖之间到达，此时同一块既被补块又被订阅推送，也不能重复
+	lis.dropAll()
+	waitFor(t, "server to drop subscription", func() bool { return chain.subscribers() == 0 })
+	tokens <- struct{}{}
+	waitFor(t, "resubscription", func() bool { return chain.subscribers() == 1 })
+	chain.mine(2) // #9、#10
+	waitFor(t, "heads 9-10", func() bool { return count() >= 10 })
+	time.Sleep(50 * time.Millisecond) // 给可能的重复输出留出时间
+
+	cancel()
+	<-done
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 10 {
+		t.Fatalf("got %d heads, want 10", len(got))
+	}
+	for i, h := range got {
+		want := chain.headers[i+1]
+		if h.Number.Uint64() != uint64(i+1) || h.Hash() != want.Hash() {
+			t.Errorf("head %d = #%s %s, want #%d %s", i, h.Number, h.Hash().Hex(), i+1, want.Hash().Hex())
+		}
+	}
+	if dials != 3 {
+		t.Errorf("dials = %d, want 3", dials)
+	}
+}
@@ -2,20 +2,18 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"fmt"
 	"log"
-	"math"
 	"math/big"
 	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types" // ← 新增这个
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 
 	store "example.com/ethclient-demo/10-deploy-contract/store" // abigen 生成的包：--pkg=store --out=store.go
+	"example.com/ethclient-demo/pkg/feeoracle"
+	"example.com/ethclient-demo/pkg/txlife"
+	"example.com/ethclient-demo/pkg/units"
+	"example.com/ethclient-demo/pkg/wallet"
 )
 
 func main() {
@@ -25,11 +23,10 @@ func main() {
 	mustOK("ethclient.Dial", err)
 	defer client.Close()
 
-	// 2) 私钥与地址
-	privateKey, err := crypto.HexToECDSA("your private key")
-	mustOK("HexToECDSA", err)
-	pub := privateKey.Public().(*ecdsa.PublicKey)
-	from := crypto.PubkeyToAddress(*pub)
+	// 2) 签名账户：设置 KEYSTORE_DIR 时用 keystore（口令见 KEYSTORE_PASS），否则退回下面的明文私钥
+	key, err := wallet.LoadSigner("your private key")
+	mustOK("load signer", err)
+	from := key.Address
 
 	// 3) 基本链上参数
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
@@ -38,26 +35,27 @@ func main() {
 	nonce, err := client.PendingNonceAt(ctx, from)
 	mustOK("PendingNonceAt", err)
 
-	gasPrice, err := client.SuggestGasPrice(ctx)
-	mustOK("SuggestGasPrice", err)
+	tipCap, feeCap, err := feeoracle.New(client).Suggest(ctx, feeoracle.Standard)
+	mustOK("feeoracle.Suggest", err)
 
 	chainID, err := client.NetworkID(ctx)
 	mustOK("NetworkID", err)
 
 	// 4) 构建交易授权
-	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
-	mustOK("NewKeyedTransactorWithChainID", err)
+	auth := key.TransactOpts(chainID)
 	auth.Nonce = big.NewInt(int64(nonce))
 	auth.Value = big.NewInt(0)     // 部署无需附带 ETH
 	auth.GasLimit = uint64(300000) // 示例值，请按实际估算
-	auth.GasPrice = gasPrice
+	auth.GasTipCap = tipCap        // 设置了 1559 费用，bind 会发动态费交易
+	auth.GasFeeCap = feeCap
 
 	// —— 打印部署前信息 ——
 	fmt.Println("[Deploy/abigen]")
 	fmt.Printf("  from:        %s (%s)\n", from.Hex(), short(from.Hex()))
 	fmt.Printf("  chainId:     %s\n", chainID.String())
 	fmt.Printf("  nonce:       %d\n", nonce)
-	fmt.Printf("  gasPrice:    %s Gwei\n", toGwei(gasPrice))
+	fmt.Printf("  tipCap:      %s Gwei\n", units.FormatGwei(tipCap))
+	fmt.Printf("  feeCap:      %s Gwei\n", units.FormatGwei(feeCap))
 	fmt.Printf("  gasLimit:    %d\n", auth.GasLimit)
 
 	// 5) 调用 abigen 部署
@@ -71,8 +69,9 @@ func main() {
 
 	_ = instance // 示例保持不使用
 
-	// 6) 等待回执（简单轮询）
-	receipt := waitReceipt(ctx, client, tx.Hash())
+	// 6) 等待回执（txlife 跟踪；revert / 被丢弃 / 被替换都会返回错误）
+	receipt, err := txlife.Wait(ctx, client, tx)
+	mustOK("wait receipt", err)
 	fmt.Printf("  mined:       block=%d  status=%d  gasUsed=%d\n",
 		receipt.BlockNumber.Uint64(), receipt.Status, receipt.GasUsed)
 	fmt.Printf("  contract:    %s\n", receipt.ContractAddress.Hex())
@@ -88,32 +87,9 @@ func mustOK(tag string, err error) {
 	}
 }
 
-func toGwei(wei *big.Int) string {
-	if wei == nil {
-		return "0"
-	}
-	f := new(big.Float).SetInt(wei)
-	g := new(big.Float).Quo(f, big.NewFloat(math.Pow10(9)))
-	return g.Text('f', 2)
-}
-
 func short(hex string) string {
 	if len(hex) <= 12 {
 		return hex
 	}
 	return fmt.Sprintf("%s...%s", hex[:6], hex[len(hex)-4:])
 }
-
-func waitReceipt(ctx context.Context, c *ethclient.Client, tx common.Hash) *types.Receipt {
-	for {
-		r, err := c.TransactionReceipt(ctx, tx)
-		if err == nil && r != nil {
-			return r
-		}
-		select {
-		case <-ctx.Done():
-			log.Fatalf("[ERR] wait receipt timeout: %v", ctx.Err())
-		case <-time.After(2 * time.Second):
-		}
-	}
-}
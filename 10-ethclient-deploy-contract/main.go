@@ -2,19 +2,20 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"encoding/hex"
 	"fmt"
 	"log"
-	"math"
 	"math/big"
 	"time"
 
-	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+
+	"example.com/ethclient-demo/pkg/feeoracle"
+	"example.com/ethclient-demo/pkg/nonce"
+	"example.com/ethclient-demo/pkg/txlife"
+	"example.com/ethclient-demo/pkg/units"
+	"example.com/ethclient-demo/pkg/wallet"
 )
 
 // 这里放你的 Store_sol_Store.bin 内容（纯十六进制，无 0x）
@@ -26,21 +27,19 @@ func main() {
 	mustOK("ethclient.Dial", err)
 	defer client.Close()
 
-	// 2) 私钥与地址
-	privateKey, err := crypto.HexToECDSA("your private key")
-	mustOK("HexToECDSA", err)
-	pub := privateKey.Public().(*ecdsa.PublicKey)
-	from := crypto.PubkeyToAddress(*pub)
+	// 2) 签名账户：设置 KEYSTORE_DIR 时用 keystore（口令见 KEYSTORE_PASS），否则退回下面的明文私钥
+	key, err := wallet.LoadSigner("your private key")
+	mustOK("load signer", err)
+	from := key.Address
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	// 3) 账户 nonce / gas / chainId
-	nonce, err := client.PendingNonceAt(ctx, from)
-	mustOK("PendingNonceAt", err)
+	// 3) gas / chainId；nonce 交给 nonce.Manager 分配
+	nonces := nonce.NewManager(client)
 
-	gasPrice, err := client.SuggestGasPrice(ctx)
-	mustOK("SuggestGasPrice", err)
+	tipCap, feeCap, err := feeoracle.New(client).Suggest(ctx, feeoracle.Standard)
+	mustOK("feeoracle.Suggest", err)
 
 	chainID, err := client.NetworkID(ctx)
 	mustOK("NetworkID", err)
@@ -49,29 +48,38 @@ func main() {
 	fmt.Println("[Deploy/raw-tx]")
 	fmt.Printf("  from:        %s (%s)\n", from.Hex(), short(from.Hex()))
 	fmt.Printf("  chainId:     %s\n", chainID.String())
-	fmt.Printf("  nonce:       %d\n", nonce)
-	fmt.Printf("  gasPrice:    %s Gwei\n", toGwei(gasPrice))
+	fmt.Printf("  tipCap:      %s Gwei\n", units.FormatGwei(tipCap))
+	fmt.Printf("  feeCap:      %s Gwei\n", units.FormatGwei(feeCap))
 
-	// 4) 解码字节码并构造创建合约交易（legacy 示例）
+	// 4) 解码字节码并构造创建合约交易（EIP-1559，To 为空即合约创建）
 	data, err := hex.DecodeString(contractBytecode)
 	mustOK("hex.DecodeString(bytecode)", err)
 
 	gasLimit := uint64(3_000_000) // 示例上限，建议先估算再加 buffer
-	tx := types.NewContractCreation(nonce, big.NewInt(0), gasLimit, gasPrice, data)
-
-	// 5) 签名并发送
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
-	mustOK("SignTx", err)
 
-	err = client.SendTransaction(ctx, signedTx)
+	// 5) 分配 nonce、签名并发送（广播失败的 nonce 会被回收）
+	signedTx, err := nonces.Send(ctx, from, func(n uint64) (*types.Transaction, error) {
+		tx := types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     n,
+			Value:     big.NewInt(0),
+			Gas:       gasLimit,
+			GasTipCap: tipCap,
+			GasFeeCap: feeCap,
+			Data:      data,
+		})
+		return key.SignTx(tx, chainID)
+	}, client.SendTransaction)
 	mustOK("SendTransaction", err)
 
 	// —— 打印发送结果 ——
+	fmt.Printf("  nonce:       %d\n", signedTx.Nonce())
 	fmt.Printf("  tx.hash:     %s\n", signedTx.Hash().Hex())
 	fmt.Printf("  progress:    broadcasted, waiting to be mined...\n")
 
 	// 6) 等待回执
-	receipt := waitReceipt(ctx, client, signedTx.Hash())
+	receipt, err := txlife.Wait(ctx, client, signedTx)
+	mustOK("wait receipt", err)
 	fmt.Printf("  mined:       block=%d  status=%d  gasUsed=%d\n",
 		receipt.BlockNumber.Uint64(), receipt.Status, receipt.GasUsed)
 	fmt.Printf("  contract:    %s\n", receipt.ContractAddress.Hex())
@@ -86,35 +94,9 @@ func mustOK(tag string, err error) {
 	}
 }
 
-func toGwei(wei *big.Int) string {
-	if wei == nil {
-		return "0"
-	}
-	f := new(big.Float).SetInt(wei)
-	g := new(big.Float).Quo(f, big.NewFloat(math.Pow10(9)))
-	return g.Text('f', 2)
-}
-
 func short(hex string) string {
 	if len(hex) <= 12 {
 		return hex
 	}
 	return fmt.Sprintf("%s...%s", hex[:6], hex[len(hex)-4:])
 }
-
-func waitReceipt(ctx context.Context, c *ethclient.Client, tx common.Hash) *types.Receipt {
-	for {
-		r, err := c.TransactionReceipt(ctx, tx)
-		if err == nil && r != nil {
-			return r
-		}
-		if err != nil && err != ethereum.NotFound {
-			log.Fatalf("[ERR] TransactionReceipt: %v", err)
-		}
-		select {
-		case <-ctx.Done():
-			log.Fatalf("[ERR] wait receipt timeout: %v", ctx.Err())
-		case <-time.After(2 * time.Second):
-		}
-	}
-}
@@ -2,11 +2,9 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"encoding/hex"
 	"fmt"
 	"log"
-	"math"
 	"math/big"
 	"strings"
 	"time"
@@ -15,8 +13,12 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+
+	"example.com/ethclient-demo/pkg/feeoracle"
+	"example.com/ethclient-demo/pkg/txlife"
+	"example.com/ethclient-demo/pkg/units"
+	"example.com/ethclient-demo/pkg/wallet"
 )
 
 const (
@@ -36,16 +38,16 @@ func main() {
 	defer cancel()
 
 	// 2) 账户&链参数
-	priv, err := crypto.HexToECDSA(privateKeyHx)
-	mustOK("HexToECDSA", err)
-	pub := priv.Public().(*ecdsa.PublicKey)
-	from := crypto.PubkeyToAddress(*pub)
+	// 设置 KEYSTORE_DIR 时用 keystore 账户签名（口令见 KEYSTORE_PASS），否则退回上面的明文私钥
+	signer, err := wallet.LoadSigner(privateKeyHx)
+	mustOK("load signer", err)
+	from := signer.Address
 
 	nonce, err := client.PendingNonceAt(ctx, from)
 	mustOK("PendingNonceAt", err)
 
-	gasPrice, err := client.SuggestGasPrice(ctx)
-	mustOK("SuggestGasPrice", err)
+	tipCap, feeCap, err := feeoracle.New(client).Suggest(ctx, feeoracle.Standard)
+	mustOK("feeoracle.Suggest", err)
 
 	chainID, err := client.NetworkID(ctx)
 	mustOK("NetworkID", err)
@@ -58,7 +60,8 @@ func main() {
 	fmt.Printf("  from:       %s (%s)\n", from.Hex(), short(from.Hex()))
 	fmt.Printf("  to:         %s (%s)\n", to.Hex(), short(to.Hex()))
 	fmt.Printf("  nonce:      %d\n", nonce)
-	fmt.Printf("  gasPrice:   %s Gwei\n", toGwei(gasPrice))
+	fmt.Printf("  tipCap:     %s Gwei\n", units.FormatGwei(tipCap))
+	fmt.Printf("  feeCap:     %s Gwei\n", units.FormatGwei(feeCap))
 
 	// 3) 解析 ABI（直接内联 JSON，生产可读取 .abi 文件）
 	const storeABI = `[{"inputs":[{"internalType":"string","name":"_version","type":"string"}],"stateMutability":"nonpayable","type":"constructor"},{"anonymous":false,"inputs":[{"indexed":false,"internalType":"bytes32","name":"key","type":"bytes32"},{"indexed":false,"internalType":"bytes32","name":"value","type":"bytes32"}],"name":"ItemSet","type":"event"},{"inputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"name":"items","outputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"bytes32","name":"key","type":"bytes32"},{"internalType":"bytes32","name":"value","type":"bytes32"}],"name":"setItem","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[],"name":"version","outputs":[{"internalType":"string","name":"","type":"string"}],"stateMutability":"view","type":"function"}]`
@@ -77,11 +80,20 @@ func main() {
 	input, err := contractABI.Pack("setItem", key, value)
 	mustOK("ABI.Pack(setItem)", err)
 
-	// 6) 构造&签名&发送交易（legacy 示例；也可改 EIP-1559）
+	// 6) 构造&签名&发送交易（EIP-1559）
 	gasLimit := uint64(300000) // 示例值，生产建议 EstimateGas 再加 buffer
-	tx := types.NewTransaction(nonce, to, big.NewInt(0), gasLimit, gasPrice, input)
-
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), priv)
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		To:        &to,
+		Value:     big.NewInt(0),
+		Gas:       gasLimit,
+		GasTipCap: tipCap,
+		GasFeeCap: feeCap,
+		Data:      input,
+	})
+
+	signedTx, err := signer.SignTx(tx, chainID)
 	mustOK("SignTx", err)
 
 	err = client.SendTransaction(ctx, signedTx)
@@ -90,8 +102,8 @@ func main() {
 	fmt.Printf("  progress:   broadcasted, waiting to be mined...\n")
 
 	// 7) 等待回执
-	rcpt, err := waitForReceipt(ctx, client, signedTx.Hash())
-	mustOK("waitForReceipt", err)
+	rcpt, err := txlife.Wait(ctx, client, signedTx)
+	mustOK("wait receipt", err)
 	fmt.Printf("  mined:      block=%d  status=%d  gasUsed=%d\n",
 		rcpt.BlockNumber.Uint64(), rcpt.Status, rcpt.GasUsed)
 
@@ -118,23 +130,6 @@ func main() {
 
 // ================= 工具函数（只为更好的打印） =================
 
-func waitForReceipt(ctx context.Context, c *ethclient.Client, h common.Hash) (*types.Receipt, error) {
-	for {
-		r, err := c.TransactionReceipt(ctx, h)
-		if err == nil && r != nil {
-			return r, nil
-		}
-		if err != nil && err != ethereum.NotFound {
-			return nil, err
-		}
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-time.After(2 * time.Second):
-		}
-	}
-}
-
 func mustOK(tag string, err error) {
 	if err != nil {
 		log.Fatalf("[ERR] %s: %v", tag, err)
@@ -147,12 +142,3 @@ func short(hex string) string {
 	}
 	return fmt.Sprintf("%s...%s", hex[:6], hex[len(hex)-4:])
 }
-
-func toGwei(wei *big.Int) string {
-	if wei == nil {
-		return "0"
-	}
-	f := new(big.Float).SetInt(wei)
-	g := new(big.Float).Quo(f, big.NewFloat(math.Pow10(9)))
-	return g.Text('f', 2)
-}
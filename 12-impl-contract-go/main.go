@@ -2,22 +2,19 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"encoding/hex"
 	"fmt"
 	"log"
-	"math"
-	"math/big"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 
 	// ⚠️ 按你的 go.mod 替换为实际路径
-	store "example.com/ethclient-demo/12-impl-contract/store"
+	store "example.com/ethclient-demo/12-impl-contract-go/store"
+	"example.com/ethclient-demo/pkg/txlife"
+	"example.com/ethclient-demo/pkg/wallet"
 )
 
 const (
@@ -40,9 +37,10 @@ func main() {
 	chainID, err := client.NetworkID(ctx)
 	mustOK("NetworkID", err)
 
-	priv, err := crypto.HexToECDSA(privHex)
-	mustOK("HexToECDSA", err)
-	from := crypto.PubkeyToAddress(*priv.Public().(*ecdsa.PublicKey))
+	// 设置 KEYSTORE_DIR 时用 keystore 账户签名（口令见 KEYSTORE_PASS），否则退回上面的明文私钥
+	signer, err := wallet.LoadSigner(privHex)
+	mustOK("load signer", err)
+	from := signer.Address
 
 	// 3) 加载合约实例
 	addr := common.HexToAddress(contractAddr)
@@ -65,8 +63,7 @@ func main() {
 	fmt.Printf("  value:      0x%s\n", hex.EncodeToString(value[:]))
 
 	// 5) 交易选项（EIP-155）
-	txOpt, err := bind.NewKeyedTransactorWithChainID(priv, chainID)
-	mustOK("NewKeyedTransactorWithChainID", err)
+	txOpt := signer.TransactOpts(chainID)
 	// txOpt.GasPrice / GasFeeCap / GasTipCap / GasLimit 留空，交给节点估算更稳（也可手动设置）
 
 	// 6) 发送交易（写操作 -> sendRawTransaction）
@@ -76,7 +73,8 @@ func main() {
 	fmt.Printf("  progress:   broadcasted, waiting to be mined...\n")
 
 	// 7) 等待上链并打印回执
-	rcpt := waitReceipt(ctx, client, tx.Hash())
+	rcpt, err := txlife.Wait(ctx, client, tx)
+	mustOK("wait receipt", err)
 	fmt.Printf("  mined:      block=%d  status=%d  gasUsed=%d\n",
 		rcpt.BlockNumber.Uint64(), rcpt.Status, rcpt.GasUsed)
 
@@ -95,20 +93,6 @@ func main() {
 
 // ============== 打印与辅助 ==============
 
-func waitReceipt(ctx context.Context, c *ethclient.Client, txHash common.Hash) *types.Receipt {
-	for {
-		r, err := c.TransactionReceipt(ctx, txHash)
-		if err == nil && r != nil {
-			return r
-		}
-		select {
-		case <-ctx.Done():
-			log.Fatalf("[ERR] wait receipt timeout: %v", ctx.Err())
-		case <-time.After(2 * time.Second):
-		}
-	}
-}
-
 func mustOK(tag string, err error) {
 	if err != nil {
 		log.Fatalf("[ERR] %s: %v", tag, err)
@@ -121,12 +105,3 @@ func short(hex string) string {
 	}
 	return fmt.Sprintf("%s...%s", hex[:6], hex[len(hex)-4:])
 }
-
-func toGwei(wei *big.Int) string {
-	if wei == nil {
-		return "0"
-	}
-	f := new(big.Float).SetInt(wei)
-	g := new(big.Float).Quo(f, big.NewFloat(math.Pow10(9)))
-	return g.Text('f', 2)
-}
@@ -2,12 +2,10 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
-	"math"
 	"math/big"
 	"time"
 
@@ -16,6 +14,12 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+
+	"example.com/ethclient-demo/pkg/feeoracle"
+	"example.com/ethclient-demo/pkg/nonce"
+	"example.com/ethclient-demo/pkg/txlife"
+	"example.com/ethclient-demo/pkg/units"
+	"example.com/ethclient-demo/pkg/wallet"
 )
 
 const (
@@ -35,18 +39,17 @@ func main() {
 	defer cancel()
 
 	// 2) 账户&链参数
-	priv, err := crypto.HexToECDSA(privateKeyHx)
-	mustOK("HexToECDSA", err)
-	pub := priv.Public().(*ecdsa.PublicKey)
-	from := crypto.PubkeyToAddress(*pub)
+	// 设置 KEYSTORE_DIR 时用 keystore 账户签名（口令见 KEYSTORE_PASS），否则退回上面的明文私钥
+	signer, err := wallet.LoadSigner(privateKeyHx)
+	mustOK("load signer", err)
+	from := signer.Address
 	to := common.HexToAddress(contractAddr)
 
 	chainID, err := client.NetworkID(ctx)
 	mustOK("NetworkID", err)
-	nonce, err := client.PendingNonceAt(ctx, from)
-	mustOK("PendingNonceAt", err)
-	gasPrice, err := client.SuggestGasPrice(ctx)
-	mustOK("SuggestGasPrice", err)
+	nonces := nonce.NewManager(client) // 本地分配 nonce，首次分配时同步 PendingNonceAt
+	tipCap, feeCap, err := feeoracle.New(client).Suggest(ctx, feeoracle.Standard)
+	mustOK("feeoracle.Suggest", err)
 
 	// —— 打印上下文信息 ——
 	fmt.Println("[Contract/execute without ABI]")
@@ -54,8 +57,8 @@ func main() {
 	fmt.Printf("  chainId:    %s\n", chainID.String())
 	fmt.Printf("  from:       %s (%s)\n", from.Hex(), short(from.Hex()))
 	fmt.Printf("  to:         %s (%s)\n", to.Hex(), short(to.Hex()))
-	fmt.Printf("  nonce:      %d\n", nonce)
-	fmt.Printf("  gasPrice:   %s Gwei\n", toGwei(gasPrice))
+	fmt.Printf("  tipCap:     %s Gwei\n", units.FormatGwei(tipCap))
+	fmt.Printf("  feeCap:     %s Gwei\n", units.FormatGwei(feeCap))
 
 	// 3) 业务入参 bytes32（Store.setItem(bytes32,bytes32)）
 	var key, value [32]byte
@@ -74,19 +77,27 @@ func main() {
 
 	// 5) 构造、签名并发送交易
 	gasLimit := uint64(300000) // 示例值；生产建议 EstimateGas 再加 buffer
-	tx := types.NewTransaction(nonce, to, big.NewInt(0), gasLimit, gasPrice, input)
-
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), priv)
-	mustOK("SignTx", err)
-
-	err = client.SendTransaction(ctx, signedTx)
+	signedTx, err := nonces.Send(ctx, from, func(n uint64) (*types.Transaction, error) {
+		tx := types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     n,
+			To:        &to,
+			Value:     big.NewInt(0),
+			Gas:       gasLimit,
+			GasTipCap: tipCap,
+			GasFeeCap: feeCap,
+			Data:      input,
+		})
+		return signer.SignTx(tx, chainID)
+	}, client.SendTransaction)
 	mustOK("SendTransaction", err)
+	fmt.Printf("  nonce:      %d\n", signedTx.Nonce())
 	fmt.Printf("  tx.hash:    %s\n", signedTx.Hash().Hex())
 	fmt.Printf("  progress:   broadcasted, waiting to be mined...\n")
 
 	// 6) 等待回执
-	rcpt, err := waitForReceipt(ctx, client, signedTx.Hash())
-	mustOK("waitForReceipt", err)
+	rcpt, err := txlife.Wait(ctx, client, signedTx)
+	mustOK("wait receipt", err)
 	fmt.Printf("  mined:      block=%d  status=%d  gasUsed=%d\n",
 		rcpt.BlockNumber.Uint64(), rcpt.Status, rcpt.GasUsed)
 
@@ -118,23 +129,6 @@ func main() {
 
 // ============== 辅助函数（仅为更好的打印） ==============
 
-func waitForReceipt(ctx context.Context, c *ethclient.Client, h common.Hash) (*types.Receipt, error) {
-	for {
-		r, err := c.TransactionReceipt(ctx, h)
-		if err == nil && r != nil {
-			return r, nil
-		}
-		if err != nil && err != ethereum.NotFound {
-			return nil, err
-		}
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-time.After(2 * time.Second):
-		}
-	}
-}
-
 func mustOK(tag string, err error) {
 	if err != nil {
 		log.Fatalf("[ERR] %s: %v", tag, err)
@@ -147,12 +141,3 @@ func short(s string) string {
 	}
 	return fmt.Sprintf("%s...%s", s[:6], s[len(s)-4:])
 }
-
-func toGwei(wei *big.Int) string {
-	if wei == nil {
-		return "0"
-	}
-	f := new(big.Float).SetInt(wei)
-	g := new(big.Float).Quo(f, big.NewFloat(math.Pow10(9)))
-	return g.Text('f', 2)
-}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// logClient 是索引器需要的节点能力；*ethclient.Client 满足
+type logClient interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+	SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error)
+}
+
+// logIndexer 分页拉取历史日志并落盘检查点，追上链尖后切到实时订阅。
+// 语义是 at-least-once：进程在一个区块处理到一半时退出，重启后会从该区块重新投递。
+type logIndexer struct {
+	client     logClient
+	query      ethereum.FilterQuery // 只用 Addresses / Topics，区间由索引器控制
+	checkpoint string               // 检查点文件路径
+	handle     func(types.Log) error
+
+	chunk    uint64        // 当前分页大小，遇到“结果过多”减半，连续成功 growAfter 次后翻倍
+	maxChunk uint64        // 分页上限
+	okStreak int           // 当前分页大小下连续成功的次数
+	poll     time.Duration // 节点不支持订阅（HTTP）时的轮询间隔
+
+	minBackoff time.Duration // 节点出错 / 订阅断开后的首次重试等待，之后翻倍
+	maxBackoff time.Duration
+}
+
+// nodeErr 标记来自节点的错误（查询、订阅、断线）：Run 按退避重试，从检查点接着追；
+// 处理回调和写检查点的错误不包这一层，仍然直接返回
+type nodeErr struct{ error }
+
+func (e nodeErr) Unwrap() error { return e.error }
+
+// errSubscriptionLost：已建立的订阅断开（例如 websocket 掉线）
+var errSubscriptionLost = errors.New("subscription lost")
+
+// growAfter：收缩后至少连续成功这么多页才尝试放大，避免在密集区间反复“翻倍→失败→减半”
+const growAfter = 5
+
+func newLogIndexer(c logClient, q ethereum.FilterQuery, checkpoint string, handle func(types.Log) error) *logIndexer {
+	return &logIndexer{
+		client:     c,
+		query:      q,
+		checkpoint: checkpoint,
+		handle:     handle,
+		chunk:      2_000,
+		maxChunk:   10_000,
+		poll:       12 * time.Second,
+		minBackoff: time.Second,
+		maxBackoff: 30 * time.Second,
+	}
+}
+
+// Run 从检查点（没有则从 start）开始索引，直到 ctx 取消或遇到不可恢复的错误。
+// 节点出错或订阅断开时不退出：等待 backoff（1s 起步、翻倍、封顶 30s）后从检查点重新追历史、重新订阅。
+func (ix *logIndexer) Run(ctx context.Context, start uint64) error {
+	from := start
+	if last, ok, err := ix.loadCheckpoint(); err != nil {
+		return err
+	} else if ok {
+		from = last + 1
+		log.Printf("[OK ] resume from checkpoint: block %d", from)
+	}
+
+	backoff := ix.minBackoff
+	for {
+		// 1) 追历史：分页 FilterLogs 直到链尖
+		next, err := ix.catchUp(ctx, from)
+		from = next
+
+		// 2) 实时：订阅新日志；节点不支持订阅则定时再追一次
+		if err == nil {
+			next, err = ix.live(ctx, from)
+			from = next
+		}
+
+		wait := ix.poll
+		var ne nodeErr
+		switch {
+		case errors.Is(err, rpc.ErrNotificationsUnsupported):
+			backoff = ix.minBackoff
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.As(err, &ne):
+			if errors.Is(err, errSubscriptionLost) {
+				backoff = ix.minBackoff // 订阅成功跑过一段，退避从头算
+			}
+			log.Printf("[WARN] %v; resume from block %d in %v", err, from, backoff)
+			wait = backoff
+			backoff = min(backoff*2, ix.maxBackoff)
+		default:
+			return err
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// catchUp 处理 [from, 当前链尖]，返回下一个待处理的区块号
+func (ix *logIndexer) catchUp(ctx context.Context, from uint64) (uint64, error) {
+	head, err := ix.client.BlockNumber(ctx)
+	if err != nil {
+		return from, nodeErr{fmt.Errorf("block number: %w", err)}
+	}
+	for from <= head {
+		to := from + ix.chunk - 1
+		if to > head {
+			to = head
+		}
+		q := ix.query
+		q.FromBlock = new(big.Int).SetUint64(from)
+		q.ToBlock = new(big.Int).SetUint64(to)
+
+		logs, err := ix.client.FilterLogs(ctx, q)
+		if err != nil {
+			if isTooManyResults(err) && ix.chunk > 1 {
+				ix.chunk /= 2
+				ix.okStreak = 0
+				log.Printf("[WARN] range [%d, %d] too large, shrink chunk to %d", from, to, ix.chunk)
+				continue
+			}
+			return from, nodeErr{fmt.Errorf("filter logs [%d, %d]: %w", from, to, err)}
+		}
+		for _, lg := range logs {
+			if err := ix.handle(lg); err != nil {
+				return from, err
+			}
+		}
+		if err := ix.saveCheckpoint(to); err != nil {
+			return from, err
+		}
+		log.Printf("[OK ] indexed [%d, %d] logs=%d chunk=%d", from, to, len(logs), ix.chunk)
+
+		from = to + 1
+		if ix.okStreak++; ix.okStreak >= growAfter && ix.chunk < ix.maxChunk {
+			ix.chunk = min(ix.chunk*2, ix.maxChunk)
+			ix.okStreak = 0
+		}
+	}
+	return from, nil
+}
+
+// live 订阅新日志。订阅建立后先补一次 [from, 链尖]，弥补“追完历史 → 订阅生效”之间的空窗，
+// 之后每当出现更高区块的日志，就把上一个区块记为已完成写入检查点。
+func (ix *logIndexer) live(ctx context.Context, from uint64) (uint64, error) {
+	logsCh := make(chan types.Log, 64)
+	sub, err := ix.client.SubscribeFilterLogs(ctx, ix.query, logsCh)
+	if err != nil {
+		return from, nodeErr{fmt.Errorf("subscribe logs: %w", err)}
+	}
+	defer sub.Unsubscribe()
+
+	next, err := ix.catchUp(ctx, from)
+	if err != nil {
+		return from, err
+	}
+	log.Printf("[OK ] caught up at block %d, switched to live subscription", next-1)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return next, ctx.Err()
+		case err := <-sub.Err():
+			// 从 next 重新追：next 之前的区块都已写入检查点，当前区块重复投递符合 at-least-once
+			return next, nodeErr{fmt.Errorf("%w: %v", errSubscriptionLost, err)}
+		case lg := <-logsCh:
+			if lg.BlockNumber < next && !lg.Removed {
+				continue // 已在补追时处理过
+			}
+			if lg.BlockNumber > next {
+				if err := ix.saveCheckpoint(lg.BlockNumber - 1); err != nil {
+					return next, err
+				}
+				next = lg.BlockNumber
+			}
+			if err := ix.handle(lg); err != nil {
+				return next, err
+			}
+		}
+	}
+}
+
+// isTooManyResults 识别各家节点“区间/结果过多”的报错文案。
+// 只匹配完整短语：裸的 "block range" 也会出现在区间非法等错误里，裸的 "limit exceeded" 会匹配到
+// "rate limit exceeded" 这类限流报错，这些情况拆分区间重试没有意义，只会把 chunk 一路减到 1。
+func isTooManyResults(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{
+		"too many results",
+		"query returned more than",
+		"block range is too large",
+		"exceeds max block range",
+		"log response size exceeded",
+		"log response size limit exceeded",
+		"query limit exceeded",
+	} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+type checkpointFile struct {
+	LastBlock uint64 `json:"lastBlock"`
+}
+
+func (ix *logIndexer) loadCheckpoint() (uint64, bool, error) {
+	raw, err := os.ReadFile(ix.checkpoint)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("read checkpoint: %w", err)
+	}
+	var cp checkpointFile
+	if err := json.Unmarshal(raw, &cp); err != nil {
+		return 0, false, fmt.Errorf("parse checkpoint %s: %w", ix.checkpoint, err)
+	}
+	return cp.LastBlock, true, nil
+}
+
+// saveCheckpoint 先写临时文件再 rename，避免进程中途退出留下半截文件
+func (ix *logIndexer) saveCheckpoint(last uint64) error {
+	raw, err := json.Marshal(checkpointFile{LastBlock: last})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(ix.checkpoint), ".checkpoint-*")
+	if err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), ix.checkpoint); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestIsTooManyResults(t *testing.T) {
+	for _, c := range []struct {
+		msg  string
+		want bool
+	}{
+		{"query returned more than 10000 results. Try with this block range [0x1, 0x2].", true},
+		{"Log response size exceeded. You can make eth_getLogs requests with up to a 2K block range", true},
+		{"block range is too large", true},
+		{"eth_getLogs: exceeds max block range of 5000", true},
+		{"too many results, max is 10000", true},
+		{"log response size limit exceeded", true},
+		{"query limit exceeded", true},
+		// 限流：不是区间太大，不能缩小 chunk
+		{"429 Too Many Requests: rate limit exceeded", false},
+		{"daily request limit exceeded", false},
+		{"compute units per second capacity limit exceeded", false},
+		// 区间非法等错误同样不该触发拆分
+		{"invalid block range params", false},
+		{"fromBlock is greater than toBlock in block range", false},
+		{"connection reset by peer", false},
+	} {
+		if got := isTooManyResults(errors.New(c.msg)); got != c.want {
+			t.Errorf("isTooManyResults(%q) = %v, want %v", c.msg, got, c.want)
+		}
+	}
+}
+
+type fakeSub struct {
+	errc chan error
+	once sync.Once
+}
+
+func (s *fakeSub) Err() <-chan error { return s.errc }
+func (s *fakeSub) Unsubscribe()      { s.once.Do(func() { close(s.errc) }) }
+
+// fakeLogs 是节点替身：FilterLogs 按区间返回内存里的日志，订阅由测试手动推送 / 掐断
+type fakeLogs struct {
+	mu       sync.Mutex
+	head     uint64
+	logs     []types.Log
+	headErrs int // BlockNumber 接下来失败的次数（模拟节点仍未恢复）
+	subs     []*fakeSub
+	ch       chan<- types.Log
+}
+
+func (f *fakeLogs) BlockNumber(ctx context.Context) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.headErrs > 0 {
+		f.headErrs--
+		return 0, errors.New("dial tcp: connection refused")
+	}
+	return f.head, nil
+}
+
+func (f *fakeLogs) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []types.Log
+	for _, lg := range f.logs {
+		if lg.BlockNumber >= q.FromBlock.Uint64() && lg.BlockNumber <= q.ToBlock.Uint64() {
+			out = append(out, lg)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeLogs) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := &fakeSub{errc: make(chan error, 1)}
+	f.subs = append(f.subs, s)
+	f.ch = ch
+	return s, nil
+}
+
+func (f *fakeLogs) subscriptions() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.subs)
+}
+
+// mine 把链尖推到 n，并在 n 上放一条日志；push 为 true 时同时经当前订阅推送
+func (f *fakeLogs) mine(n uint64, push bool) {
+	f.mu.Lock()
+	lg := types.Log{BlockNumber: n, TxHash: common.BigToHash(new(big.Int).SetUint64(n))}
+	f.head = n
+	f.logs = append(f.logs, lg)
+	ch := f.ch
+	f.mu.Unlock()
+	if push {
+		ch <- lg
+	}
+}
+
+func TestLiveResubscribe(t *testing.T) {
+	f := &fakeLogs{head: 10, logs: []types.Log{{BlockNumber: 3}, {BlockNumber: 7}}}
+	var (
+		mu  sync.Mutex
+		got []uint64
+	)
+	seen := func() []uint64 {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(got)
+	}
+	checkpoint := filepath.Join(t.TempDir(), "checkpoint.json")
+	ix := newLogIndexer(f, ethereum.FilterQuery{}, checkpoint, func(lg types.Log) error {
+		mu.Lock()
+		got = append(got, lg.BlockNumber)
+		mu.Unlock()
+		return nil
+	})
+	ix.minBackoff, ix.maxBackoff = time.Millisecond, 5*time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- ix.Run(ctx, 1) }()
+
+	waitUntil := func(what string, cond func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timeout waiting for %s (handled %v)", what, seen())
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	waitUntil("first subscription", func() bool { return f.subscriptions() == 1 })
+	f.mine(12, true)
+	waitUntil("live log 12", func() bool { return len(seen()) == 3 })
+
+	// 断线：订阅报错，断线期间出了 #13，节点恢复前 BlockNumber 还会失败两次
+	f.mu.Lock()
+	f.headErrs = 2
+	sub := f.subs[0]
+	f.mu.Unlock()
+	sub.errc <- errors.New("websocket: close 1006 (abnormal closure)")
+	f.mine(13, false)
+	waitUntil("resubscription", func() bool { return f.subscriptions() == 2 })
+	waitUntil("missed log 13", func() bool { return slices.Contains(seen(), 13) })
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run = %v, want context.Canceled", err)
+	}
+	// at-least-once：断线时所在的区块 12 可能重复投递，但不能漏块、不能倒退
+	h := seen()
+	if !slices.IsSorted(h) {
+		t.Errorf("handled out of order: %v", h)
+	}
+	for _, n := range []uint64{3, 7, 12, 13} {
+		if !slices.Contains(h, n) {
+			t.Errorf("log at block %d not handled: %v", n, h)
+		}
+	}
+	if last, ok, err := ix.loadCheckpoint(); err != nil || !ok || last != 13 {
+		t.Errorf("checkpoint = %d, %v, %v, want 13", last, ok, err)
+	}
+}
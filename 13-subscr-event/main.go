@@ -2,7 +2,7 @@ package main
 
 import (
 	"context"
-	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -11,13 +11,14 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+
+	"example.com/ethclient-demo/pkg/eventdec"
 )
 
 const (
 	wsURL    = "wss://eth-sepolia.g.alchemy.com/v2/xxx" // Rinkeby 已下线，用 Sepolia/Goerli 等
-	storeABI = `[{"inputs":[{"internalType":"string","name":"_version","type":"string"}],"stateMutability":"nonpayable","type":"constructor"},{"anonymous":false,"inputs":[{"indexed":true,"internalType":"bytes32","name":"key","type":"bytes32"},{"indexed":false,"internalType":"bytes32","name":"value","type":"bytes32"}],"name":"ItemSet","type":"event"},{"inputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"name":"items","outputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"bytes32","name":"key","type":"bytes32"},{"internalType":"bytes32","name":"value","type":"bytes32"}],"name":"setItem","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[],"name":"version","outputs":[{"internalType":"string","name":"","type":"string"}],"stateMutability":"view","type":"function"}]`
+	storeABI = `[{"inputs":[{"internalType":"string","name":"_version","type":"string"}],"stateMutability":"nonpayable","type":"constructor"},{"anonymous":false,"inputs":[{"indexed":false,"internalType":"bytes32","name":"key","type":"bytes32"},{"indexed":false,"internalType":"bytes32","name":"value","type":"bytes32"}],"name":"ItemSet","type":"event"},{"inputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"name":"items","outputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"bytes32","name":"key","type":"bytes32"},{"internalType":"bytes32","name":"value","type":"bytes32"}],"name":"setItem","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[],"name":"version","outputs":[{"internalType":"string","name":"","type":"string"}],"stateMutability":"view","type":"function"}]`
 )
 
 func main() {
@@ -33,10 +34,10 @@ func main() {
 	sub, err := client.SubscribeFilterLogs(context.Background(), query, logsCh)
 	mustOK("SubscribeFilterLogs", err)
 
-	// 准备 ABI 与事件签名
+	// 准备 ABI 与事件定义（topic0 = itemSet.ID）
 	parsed, err := abi.JSON(strings.NewReader(storeABI))
 	mustOK("abi.JSON", err)
-	sigHash := crypto.Keccak256Hash([]byte("ItemSet(bytes32,bytes32)"))
+	itemSet := parsed.Events["ItemSet"]
 
 	fmt.Println("[Events/subscribe]")
 	fmt.Printf("  ws:       %s\n", wsURL)
@@ -53,27 +54,21 @@ func main() {
 			fmt.Printf("  block:   %d (%s)\n", lg.BlockNumber, lg.BlockHash.Hex())
 			fmt.Printf("  tx:      %s\n", lg.TxHash.Hex())
 
-			if len(lg.Topics) == 0 || lg.Topics[0] != sigHash {
+			// 按 ABI 的 Indexed 标记拆分 topics / data，解出全部参数
+			dec, err := eventdec.DecodeLog(&itemSet, lg)
+			if errors.Is(err, eventdec.ErrTopicMismatch) {
 				fmt.Printf("  note:    non-ItemSet topic0=%v\n", lg.Topics)
 				continue
 			}
-			// 解码 value（非 indexed）
-			var data struct{ Value [32]byte }
-			if err := parsed.UnpackIntoInterface(&data, "ItemSet", lg.Data); err != nil {
-				log.Printf("[WARN] unpack ItemSet data: %v", err)
+			if err != nil {
+				log.Printf("[WARN] decode ItemSet: %v", err)
 				continue
 			}
-			// 读取 indexed 的 key（topics[1]）
-			key := ""
-			if len(lg.Topics) > 1 {
-				key = lg.Topics[1].Hex()
-			}
 
 			fmt.Printf("  topic0:  %s\n", lg.Topics[0].Hex())
-			if key != "" {
-				fmt.Printf("  key:     %s\n", key)
+			for j, name := range dec.Names {
+				fmt.Printf("  %-8s %s\n", name+":", eventdec.FormatArg(dec.Event.Inputs[j].Type, dec.Args[name]))
 			}
-			fmt.Printf("  value:   0x%s\n", hex.EncodeToString(data.Value[:]))
 		}
 	}
 }
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"example.com/ethclient-demo/pkg/feeoracle"
+	"example.com/ethclient-demo/pkg/txlife"
+	"example.com/ethclient-demo/pkg/units"
+	"example.com/ethclient-demo/pkg/wallet"
+)
+
+// runReplace 对一笔卡在交易池里的交易做同 nonce 替换：
+//
+//	speedup：原样重发，只把 GasTipCap / GasFeeCap 提高（至少 10%，节点替换规则要求）
+//	cancel： 改成 0 value 的自转账，同样加价，原交易作废
+func runReplace(mode string, hash common.Hash) {
+	key := loadSigner("") // 位置参数已被子命令占用，私钥只能来自 keystore 或 PRIV_KEY_HEX
+	rpcURL := getenv("SEPOLIA_RPC", defaultRPC)
+	percent := int64(getenvInt("BUMP_PERCENT", txlife.MinBumpPercent))
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	mustOK("ethclient.Dial", err)
+	defer client.Close()
+
+	from := key.Address
+
+	// 1) 取回原交易，确认仍在 pending 且确实是本账户发出的
+	orig, pending, err := client.TransactionByHash(ctx, hash)
+	mustOK("TransactionByHash", err)
+	if !pending {
+		log.Fatalf("tx %s already mined, nothing to replace", hash.Hex())
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(orig.ChainId()), orig)
+	mustOK("recover sender", err)
+	if sender != from {
+		log.Fatalf("tx %s sent by %s, not by signing account %s", hash.Hex(), sender.Hex(), from.Hex())
+	}
+
+	// 2) 当前网络建议费用：加价 10% 后仍低于它时直接取建议值
+	var minTip, minFeeCap *big.Int
+	if orig.Type() == types.DynamicFeeTxType {
+		speed, err := feeoracle.ParseSpeed(getenv("FEE_SPEED", "standard"))
+		mustOK("FEE_SPEED", err)
+		minTip, minFeeCap, err = feeoracle.New(client).Suggest(ctx, speed)
+		mustOK("feeoracle.Suggest", err)
+	} else {
+		minFeeCap, err = client.SuggestGasPrice(ctx)
+		mustOK("SuggestGasPrice", err)
+	}
+
+	// 3) 构造替换交易
+	var replacement *types.Transaction
+	if mode == "cancel" {
+		replacement, err = txlife.Cancellation(orig, from, percent, minTip, minFeeCap)
+	} else {
+		replacement, err = txlife.Bumped(orig, percent, minTip, minFeeCap)
+	}
+	mustOK("build replacement", err)
+
+	signed, err := key.SignTx(replacement, orig.ChainId())
+	mustOK("SignTx", err)
+	err = client.SendTransaction(ctx, signed)
+	if txlife.IsUnderpriced(err) {
+		log.Fatalf("[ERR] replacement underpriced, retry with a larger BUMP_PERCENT: %v", err)
+	}
+	mustOK("SendTransaction", err)
+
+	fmt.Printf("[Tx/%s]\n", mode)
+	fmt.Printf("  rpc:         %s\n", rpcURL)
+	fmt.Printf("  from:        %s (%s)\n", from.Hex(), shortStr(from.Hex()))
+	fmt.Printf("  nonce:       %d\n", orig.Nonce())
+	fmt.Printf("  original:    %s\n", orig.Hash().Hex())
+	printFees("  old fees:   ", orig)
+	printFees("  new fees:   ", signed)
+	fmt.Printf("  tx.hash:     %s\n", signed.Hash().Hex())
+	fmt.Printf("  progress:    broadcasted, waiting to be mined...\n")
+
+	rcpt, err := waitTx(client, key, signed)
+	if errors.Is(err, txlife.ErrReplaced) {
+		fmt.Println("  result:      original tx was mined before the replacement")
+		fmt.Println("[Done]")
+		return
+	}
+	mustOK("wait receipt", err)
+	fmt.Printf("  mined:       tx=%s block=%d  status=%d  gasUsed=%d\n",
+		rcpt.TxHash.Hex(), rcpt.BlockNumber.Uint64(), rcpt.Status, rcpt.GasUsed)
+	fmt.Println("[Done]")
+}
+
+// waitTx 等待交易上链。设置 AUTO_BUMP（如 "30s"）后，每隔该时长未上链就自动加价重发，
+// 最多 MAX_BUMPS 次（默认 5）；返回最终上链那一版的回执。
+func waitTx(client *ethclient.Client, key *wallet.Signer, tx *types.Transaction) (*types.Receipt, error) {
+	every := os.Getenv("AUTO_BUMP")
+	if every == "" {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		return txlife.Wait(ctx, client, tx)
+	}
+	d, err := time.ParseDuration(every)
+	if err != nil || d <= 0 {
+		return nil, fmt.Errorf("invalid AUTO_BUMP %q", every)
+	}
+	maxBumps := getenvInt("MAX_BUMPS", 5)
+
+	// 给最后一次加价之后也留出等待时间
+	ctx, cancel := context.WithTimeout(context.Background(), d*time.Duration(maxBumps+2))
+	defer cancel()
+
+	bumper := &txlife.Bumper{
+		Tracker:  txlife.NewTracker(client, txlife.Config{}),
+		Sign:     key.SignTx,
+		Send:     client.SendTransaction,
+		Every:    d,
+		Percent:  int64(getenvInt("BUMP_PERCENT", txlife.MinBumpPercent)),
+		MaxBumps: maxBumps,
+		OnBump: func(old, replacement *types.Transaction) {
+			fmt.Printf("  bumped:      %s -> %s\n", shortStr(old.Hash().Hex()), replacement.Hash().Hex())
+			printFees("  new fees:   ", replacement)
+		},
+	}
+	return bumper.Wait(ctx, tx, nil)
+}
+
+func printFees(label string, tx *types.Transaction) {
+	if tx.Type() == types.DynamicFeeTxType {
+		fmt.Printf("%s tip=%s Gwei  maxFee=%s Gwei\n", label, units.FormatGwei(tx.GasTipCap()), units.FormatGwei(tx.GasFeeCap()))
+		return
+	}
+	fmt.Printf("%s gasPrice=%s Gwei\n", label, units.FormatGwei(tx.GasPrice()))
+}
+
+func getenvInt(k string, def int) int {
+	v := os.Getenv(k)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Fatalf("invalid %s: %s", k, v)
+	}
+	return n
+}
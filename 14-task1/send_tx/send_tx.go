@@ -3,19 +3,21 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"log"
-	"math"
-	"math/big"
 	"os"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+
+	"example.com/ethclient-demo/pkg/feeoracle"
+	"example.com/ethclient-demo/pkg/units"
+	"example.com/ethclient-demo/pkg/wallet"
 )
 
 const (
@@ -26,25 +28,32 @@ const (
 )
 
 func main() {
+	// 子命令：speedup / cancel <txHash>，对卡住的交易做同 nonce 加价替换
+	// 子命令名不能落到下面当私钥参数用，参数不对直接给出用法
+	if len(os.Args) >= 2 && (os.Args[1] == "speedup" || os.Args[1] == "cancel") {
+		if len(os.Args) != 3 {
+			log.Fatalf("usage: go run . %s <txHash>", os.Args[1])
+		}
+		raw, err := hexutil.Decode(os.Args[2])
+		if err != nil || len(raw) != common.HashLength {
+			log.Fatalf("invalid tx hash %q (want 0x + 64 hex chars)\nusage: go run . %s <txHash>", os.Args[2], os.Args[1])
+		}
+		runReplace(os.Args[1], common.BytesToHash(raw))
+		return
+	}
+
 	// 环境变量：SEPOLIA_RPC / PRIV_KEY_HEX / TO / AMOUNT_ETH
+	// 签名：设置 KEYSTORE_DIR（+ KEYSTORE_ACCOUNT / KEYSTORE_PASS）则用 keystore 账户，不再需要 PRIV_KEY_HEX
+	// 可选：FEE_SPEED（slow/standard/fast）/ AUTO_BUMP（如 30s，超时未上链自动加价重发）/ MAX_BUMPS / BUMP_PERCENT
 	rpcURL := getenv("SEPOLIA_RPC", defaultRPC)
 	toHex := getenv("TO", defaultTo)
 	amountEth := getenv("AMOUNT_ETH", defaultETH)
 
-	if len(os.Args) < 2 && getenv("PRIV_KEY_HEX", "") == "" {
-		log.Fatalf("usage: PRIV_KEY_HEX=<hex> go run send_tx.go\n(hint) export SEPOLIA_RPC/TO/AMOUNT_ETH for convenience")
-	}
-	// privHex := getenv("PRIV_KEY_HEX", os.Args[1]) // 也支持作为第一个参数传入
-
-	// --- 原有代码附近，替换成下面这段 ---
-	privHex := os.Getenv("PRIV_KEY_HEX")
-	if privHex == "" {
-		if len(os.Args) >= 2 {
-			privHex = os.Args[1] // 允许用第一个位置参数传私钥
-		} else {
-			log.Fatalf("PRIV_KEY_HEX not set. Use: export PRIV_KEY_HEX=<hex>  or  go run send_tx.go <hex>")
-		}
+	var argKey string
+	if len(os.Args) >= 2 {
+		argKey = os.Args[1] // 允许用第一个位置参数传私钥
 	}
+	key := loadSigner(argKey)
 
 	// 1) 连接
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
@@ -55,17 +64,7 @@ func main() {
 	defer client.Close()
 
 	// 2) 账户与链参数
-	priv, err := crypto.HexToECDSA(privHex)
-	mustOK("HexToECDSA", err)
-
-	// priv 是 *ecdsa.PrivateKey
-	pubAny := priv.Public()                 // interface{}
-	pubKey, ok := pubAny.(*ecdsa.PublicKey) // 断言为 *ecdsa.PublicKey
-	if !ok {
-		log.Fatal("public key is not *ecdsa.PublicKey")
-	}
-
-	from := crypto.PubkeyToAddress(*pubKey)
+	from := key.Address
 	to := common.HexToAddress(toHex)
 
 	chainID, err := client.NetworkID(ctx)
@@ -74,19 +73,15 @@ func main() {
 	nonce, err := client.PendingNonceAt(ctx, from)
 	mustOK("PendingNonceAt", err)
 
-	// 3) EIP-1559 手续费（MaxPriorityFee：建议小费；MaxFee：粗略= BaseFee + 2*Tip）
-	tip, err := client.SuggestGasTipCap(ctx)
-	mustOK("SuggestGasTipCap", err)
-	head, err := client.HeaderByNumber(ctx, nil)
-	mustOK("HeaderByNumber", err)
-
-	maxFee := new(big.Int).Add(head.BaseFee, new(big.Int).Mul(big.NewInt(2), tip))
+	// 3) EIP-1559 手续费：feeoracle 按 FeeHistory 的小费分位数给出 tip，MaxFee 按下一块 baseFee 预留上涨空间
+	speed, err := feeoracle.ParseSpeed(getenv("FEE_SPEED", "standard"))
+	mustOK("FEE_SPEED", err)
+	tip, maxFee, err := feeoracle.New(client).Suggest(ctx, speed)
+	mustOK("feeoracle.Suggest", err)
 
 	// 4) 转账金额（ETH → wei）
-	amountWei, ok := ethToWei(amountEth)
-	if !ok {
-		log.Fatalf("invalid AMOUNT_ETH: %s", amountEth)
-	}
+	amountWei, err := units.ParseEther(amountEth)
+	mustOK("AMOUNT_ETH", err)
 
 	// 5) 估算 GasLimit
 	gasLimit, err := client.EstimateGas(ctx, ethereum.CallMsg{
@@ -111,8 +106,7 @@ func main() {
 		GasFeeCap: maxFee,
 		Data:      nil, // 普通转账无数据
 	})
-	signer := types.LatestSignerForChainID(chainID)
-	signed, err := types.SignTx(tx, signer, priv)
+	signed, err := key.SignTx(tx, chainID)
 	mustOK("SignTx", err)
 
 	// 7) 发送交易
@@ -127,55 +121,31 @@ func main() {
 	fmt.Printf("  to:          %s (%s)\n", to.Hex(), shortStr(to.Hex()))
 	fmt.Printf("  nonce:       %d\n", nonce)
 	fmt.Printf("  amount:      %s ETH\n", amountEth)
-	fmt.Printf("  tip:         %s Gwei\n", toGwei(tip))
-	fmt.Printf("  maxFee:      %s Gwei\n", toGwei(maxFee))
+	fmt.Printf("  fee speed:   %s\n", speed)
+	fmt.Printf("  tip:         %s Gwei\n", units.FormatGwei(tip))
+	fmt.Printf("  maxFee:      %s Gwei\n", units.FormatGwei(maxFee))
 	fmt.Printf("  gasLimit:    %d\n", gasLimit)
 	fmt.Printf("  tx.hash:     %s\n", signed.Hash().Hex())
 	fmt.Printf("  progress:    broadcasted, waiting to be mined...\n")
 
-	// 8) 等待上链并输出回执摘要
-	rcpt := waitReceipt(ctx, client, signed.Hash())
-	fmt.Printf("  mined:       block=%d  status=%d  gasUsed=%d\n",
-		rcpt.BlockNumber.Uint64(), rcpt.Status, rcpt.GasUsed)
+	// 8) 等待上链并输出回执摘要（开启 AUTO_BUMP 时上链的可能是加价后的版本）
+	rcpt, err := waitTx(client, key, signed)
+	mustOK("wait receipt", err)
+	fmt.Printf("  mined:       tx=%s block=%d  status=%d  gasUsed=%d\n",
+		rcpt.TxHash.Hex(), rcpt.BlockNumber.Uint64(), rcpt.Status, rcpt.GasUsed)
 	fmt.Println("[Done]")
 }
 
 // ========== utils ==========
 
-func waitReceipt(ctx context.Context, c *ethclient.Client, h common.Hash) *types.Receipt {
-	for {
-		r, err := c.TransactionReceipt(ctx, h)
-		if err == nil && r != nil {
-			return r
-		}
-		select {
-		case <-ctx.Done():
-			log.Fatalf("[ERR] wait receipt timeout: %v", ctx.Err())
-		case <-time.After(2 * time.Second):
-		}
-	}
-}
-
-func ethToWei(s string) (*big.Int, bool) {
-	// 支持最多 18 位小数的简易转换
-	// 例如 "0.001" -> 1e15 wei
-	f, ok := new(big.Float).SetString(s)
-	if !ok {
-		return nil, false
-	}
-	weiF := new(big.Float).Mul(f, big.NewFloat(math.Pow10(18)))
-	wei := new(big.Int)
-	weiF.Int(wei) // 向下取整
-	return wei, true
-}
-
-func toGwei(wei *big.Int) string {
-	if wei == nil {
-		return "0"
+// loadSigner 优先用 keystore（KEYSTORE_DIR），否则用 PRIV_KEY_HEX，再否则用 argKey
+func loadSigner(argKey string) *wallet.Signer {
+	key, err := wallet.LoadSigner(getenv("PRIV_KEY_HEX", argKey))
+	if errors.Is(err, wallet.ErrNoKeystore) {
+		log.Fatalf("usage: KEYSTORE_DIR=<dir> go run .  or  PRIV_KEY_HEX=<hex> go run .\n(hint) export SEPOLIA_RPC/TO/AMOUNT_ETH for convenience")
 	}
-	f := new(big.Float).SetInt(wei)
-	g := new(big.Float).Quo(f, big.NewFloat(math.Pow10(9)))
-	return g.Text('f', 2)
+	mustOK("load signer", err)
+	return key
 }
 
 func mustOK(tag string, err error) {
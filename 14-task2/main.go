@@ -2,18 +2,18 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
 	"os"
-	"time"
 
 	"example.com/ethclient-demo/14-task2/counter"
+	"example.com/ethclient-demo/pkg/txlife"
+	"example.com/ethclient-demo/pkg/wallet"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
@@ -21,7 +21,6 @@ func main() {
 	ctx := context.Background()
 
 	rpcURL := mustGetenv("SEPOLIA_RPC")
-	privHex := mustGetenv("PRIV_KEY_HEX")
 	chainIDStr := os.Getenv("CHAIN_ID")
 	if chainIDStr == "" {
 		chainIDStr = "11155111" // Sepolia
@@ -35,24 +34,18 @@ func main() {
 	}
 	defer client.Close()
 
-	// 2) 加载私钥与账户
-	privateKey, err := crypto.HexToECDSA(privHex)
-	if err != nil {
-		log.Fatalf("bad PRIV_KEY_HEX: %v", err)
+	// 2) 加载账户：设置 KEYSTORE_DIR 时用 keystore（口令见 KEYSTORE_PASS），否则用 PRIV_KEY_HEX
+	signer, err := wallet.LoadSigner(os.Getenv("PRIV_KEY_HEX"))
+	if errors.Is(err, wallet.ErrNoKeystore) {
+		log.Fatal("set KEYSTORE_DIR or PRIV_KEY_HEX")
 	}
-	publicKey := privateKey.Public()
-	pubECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		log.Fatal("cannot cast public key")
+	if err != nil {
+		log.Fatalf("load signer: %v", err)
 	}
-	fromAddr := crypto.PubkeyToAddress(*pubECDSA)
-	fmt.Printf("Using account: %s\n", fromAddr.Hex())
+	fmt.Printf("Using account: %s\n", signer.Address.Hex())
 
 	// 3) 构造交易授权 (EIP-1559)
-	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
-	if err != nil {
-		log.Fatalf("new transactor: %v", err)
-	}
+	auth := signer.TransactOpts(chainID)
 	// 让 geth 自动估算 gas；也可以手动设置
 	// auth.GasFeeCap / GasTipCap / GasLimit 留空交给节点估算即可
 
@@ -66,7 +59,7 @@ func main() {
 	fmt.Printf("Contract address (pending): %s\n", contractAddr.Hex())
 
 	// 等待上链
-	waitMined(ctx, client, deployTx.Hash())
+	waitMined(ctx, client, deployTx)
 	fmt.Printf("Deployed at: %s\n", contractAddr.Hex())
 
 	// 5) 读取当前值（只读调用）
@@ -82,7 +75,7 @@ func main() {
 		log.Fatalf("increment: %v", err)
 	}
 	fmt.Printf("increment() tx: %s\n", tx.Hash().Hex())
-	waitMined(ctx, client, tx.Hash())
+	waitMined(ctx, client, tx)
 
 	// 7) 再次读取
 	cur2, err := c.Current(&bind.CallOpts{Context: ctx})
@@ -92,19 +85,13 @@ func main() {
 	fmt.Printf("current() after increment: %s\n", cur2.String())
 }
 
-func waitMined(ctx context.Context, c *ethclient.Client, txHash common.Hash) {
-	for {
-		_, isPending, err := c.TransactionByHash(ctx, txHash)
-		if err != nil {
-			// 有时节点会暂时查不到，稍等重试
-			time.Sleep(2 * time.Second)
-			continue
-		}
-		if !isPending {
-			break
-		}
-		time.Sleep(2 * time.Second)
+// waitMined 等待交易上链；失败（revert / 被丢弃 / 被替换）直接退出
+func waitMined(ctx context.Context, c *ethclient.Client, tx *types.Transaction) *types.Receipt {
+	rcpt, err := txlife.Wait(ctx, c, tx)
+	if err != nil {
+		log.Fatalf("wait tx %s: %v", tx.Hash().Hex(), err)
 	}
+	return rcpt
 }
 func mustGetenv(k string) string {
 	v := os.Getenv(k)
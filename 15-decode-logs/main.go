@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"example.com/ethclient-demo/pkg/abifile"
+	"example.com/ethclient-demo/pkg/eventdec"
+)
+
+const defaultRPC = "https://eth-sepolia.g.alchemy.com/v2/xxx"
+
+// 用任意合约的 ABI 解码某个地址在区间内的全部日志：
+//
+//	go run ./15-decode-logs <abi.json> <address> <fromBlock> [toBlock]
+//
+// 环境变量：RPC_URL、OUTPUT（text | json，json 为每行一条 JSON）、CHUNK（单次 FilterLogs 区间，默认 2000）
+func main() {
+	if len(os.Args) < 4 {
+		log.Fatalf("usage: go run ./15-decode-logs <abi.json> <address> <fromBlock> [toBlock]")
+	}
+	parsed, err := abifile.Load(os.Args[1])
+	mustOK("load abi", err)
+	if !common.IsHexAddress(os.Args[2]) {
+		log.Fatalf("invalid address: %s", os.Args[2])
+	}
+	contract := common.HexToAddress(os.Args[2])
+	from, err := strconv.ParseUint(os.Args[3], 10, 64)
+	mustOK("parse fromBlock", err)
+
+	output := getenv("OUTPUT", "text")
+	if output != "text" && output != "json" {
+		log.Fatalf("invalid OUTPUT: %s (want text | json)", output)
+	}
+	chunk, err := strconv.ParseUint(getenv("CHUNK", "2000"), 10, 64)
+	if err != nil || chunk == 0 {
+		log.Fatalf("invalid CHUNK: %s", os.Getenv("CHUNK"))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	rpcURL := getenv("RPC_URL", defaultRPC)
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	mustOK("ethclient.Dial", err)
+	defer client.Close()
+
+	// 不给 toBlock 时扫到当前链尖
+	var to uint64
+	if len(os.Args) > 4 {
+		to, err = strconv.ParseUint(os.Args[4], 10, 64)
+		mustOK("parse toBlock", err)
+	} else {
+		to, err = client.BlockNumber(ctx)
+		mustOK("BlockNumber", err)
+	}
+
+	dec := eventdec.NewDecoder(parsed)
+	if output == "text" {
+		fmt.Println("[Logs/decode]")
+		fmt.Printf("  rpc:       %s\n", rpcURL)
+		fmt.Printf("  contract:  %s (%s)\n", contract.Hex(), short(contract.Hex()))
+		fmt.Printf("  range:     [%d, %d]\n", from, to)
+		fmt.Printf("  events:    %d in ABI\n", len(parsed.Events))
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	total, unknown := 0, 0
+	for start := from; start <= to; start += chunk {
+		end := min(start+chunk-1, to)
+		logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Addresses: []common.Address{contract},
+		})
+		mustOK(fmt.Sprintf("FilterLogs[%d, %d]", start, end), err)
+
+		for _, lg := range logs {
+			total++
+			d, err := dec.Decode(lg)
+			if errors.Is(err, eventdec.ErrUnknownEvent) {
+				unknown++
+			} else if err != nil {
+				log.Printf("[WARN] decode log %s#%d: %v", lg.TxHash.Hex(), lg.Index, err)
+				d = nil
+			}
+			if output == "json" {
+				mustOK("encode json", enc.Encode(toRecord(lg, d)))
+			} else {
+				printLog(total, lg, d)
+			}
+		}
+		if end == to { // 防止 to 接近 MaxUint64 时 start 溢出
+			break
+		}
+	}
+
+	if output == "text" {
+		fmt.Printf("\n[Done] logs=%d unknown=%d\n", total, unknown)
+	}
+}
+
+// logRecord 是 json 输出模式下的一行
+type logRecord struct {
+	Block     uint64         `json:"block"`
+	Tx        string         `json:"tx"`
+	LogIndex  uint           `json:"logIndex"`
+	Address   string         `json:"address"`
+	Event     string         `json:"event,omitempty"`
+	Signature string         `json:"signature,omitempty"`
+	Args      map[string]any `json:"args,omitempty"`
+	Topics    []string       `json:"topics,omitempty"` // 仅未识别的日志输出原始 topics/data
+	Data      string         `json:"data,omitempty"`
+	Removed   bool           `json:"removed,omitempty"`
+}
+
+func toRecord(lg types.Log, d *eventdec.Decoded) logRecord {
+	r := logRecord{
+		Block:    lg.BlockNumber,
+		Tx:       lg.TxHash.Hex(),
+		LogIndex: lg.Index,
+		Address:  lg.Address.Hex(),
+		Removed:  lg.Removed,
+	}
+	if d == nil {
+		for _, t := range lg.Topics {
+			r.Topics = append(r.Topics, t.Hex())
+		}
+		r.Data = hexutil.Encode(lg.Data)
+		return r
+	}
+	r.Event = d.Event.Name
+	r.Signature = d.Event.Sig
+	r.Args = make(map[string]any, len(d.Args))
+	for j, name := range d.Names {
+		r.Args[name] = eventdec.JSONValue(d.Event.Inputs[j].Type, d.Args[name])
+	}
+	return r
+}
+
+func printLog(i int, lg types.Log, d *eventdec.Decoded) {
+	fmt.Printf("\n#%d\n", i)
+	fmt.Printf("  block:   %d (%s)\n", lg.BlockNumber, lg.BlockHash.Hex())
+	fmt.Printf("  tx:      %s  logIndex=%d\n", lg.TxHash.Hex(), lg.Index)
+	if lg.Removed {
+		fmt.Printf("  removed: true\n")
+	}
+	if d == nil {
+		fmt.Printf("  event:   <unknown>\n")
+		for j, t := range lg.Topics {
+			fmt.Printf("  topic%d:  %s\n", j, t.Hex())
+		}
+		fmt.Printf("  data:    %s\n", hexutil.Encode(lg.Data))
+		return
+	}
+	fmt.Printf("  event:   %s\n", d.Event.Sig)
+	for j, name := range d.Names {
+		note := ""
+		if arg := d.Event.Inputs[j]; arg.Indexed && eventdec.IsHashedTopic(arg.Type) {
+			note = "  // indexed " + arg.Type.String() + "，topic 中只有 keccak 哈希"
+		}
+		fmt.Printf("  %-8s %s%s\n", name+":", eventdec.FormatArg(d.Event.Inputs[j].Type, d.Args[name]), note)
+	}
+}
+
+func mustOK(tag string, err error) {
+	if err != nil {
+		log.Fatalf("[ERR] %s: %v", tag, err)
+	}
+}
+
+func getenv(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
+	}
+	return def
+}
+
+func short(s string) string {
+	if len(s) <= 12 {
+		return s
+	}
+	return fmt.Sprintf("%s...%s", s[:6], s[len(s)-4:])
+}
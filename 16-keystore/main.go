@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"example.com/ethclient-demo/pkg/wallet"
+)
+
+// 管理 keystore 目录（Web3 Secret Storage v3）：
+//
+//	go run ./16-keystore new                     生成新账户
+//	go run ./16-keystore import                  导入十六进制私钥（从标准输入读取，终端下不回显）
+//	go run ./16-keystore import <keyfile.json>   导入其它钱包导出的 keystore 文件（先输入原口令）
+//	go run ./16-keystore list                    列出账户
+//
+// 私钥不接受命令行参数：参数会留在 shell 历史和 ps / /proc/*/cmdline 里。
+// 环境变量：KEYSTORE_DIR（默认 ./keystore）、KEYSTORE_PASS / KEYSTORE_PASS_FILE（不设则从终端读取）。
+// 其它程序设置 KEYSTORE_DIR（+ KEYSTORE_ACCOUNT）后即改用 keystore 签名，不再需要明文私钥。
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	dir := getenv("KEYSTORE_DIR", "keystore")
+	ks := wallet.Open(dir, false)
+
+	switch os.Args[1] {
+	case "new":
+		pass := newPassphrase()
+		acct, err := ks.Create(pass)
+		mustOK("create account", err)
+		fmt.Println("[Keystore/new]")
+		fmt.Printf("  address:     %s\n", acct.Address.Hex())
+		fmt.Printf("  file:        %s\n", acct.URL.Path)
+
+	case "import":
+		if len(os.Args) > 3 {
+			usage()
+		}
+		if len(os.Args) == 3 {
+			src := os.Args[2]
+			if !strings.HasSuffix(strings.ToLower(src), ".json") {
+				log.Fatalf("[ERR] %s is not a .json key file; private keys are read from stdin, run without arguments", src)
+			}
+			raw, err := os.ReadFile(src)
+			mustOK("read key file", err)
+			fmt.Println("[Keystore/import]")
+			// 原文件的口令只从终端读，避免和新口令的环境变量混淆
+			oldPass := readLine("Passphrase of " + src + ": ")
+			newPass := newPassphrase()
+			acct, err := ks.ImportJSON(raw, oldPass, newPass)
+			mustOK("import key file", err)
+			fmt.Printf("  address:     %s\n", acct.Address.Hex())
+			fmt.Printf("  file:        %s\n", acct.URL.Path)
+			return
+		}
+		hexKey := readLine("Private key (hex): ")
+		fmt.Println("[Keystore/import]")
+		acct, err := ks.ImportHex(hexKey, newPassphrase())
+		mustOK("import private key", err)
+		fmt.Printf("  address:     %s\n", acct.Address.Hex())
+		fmt.Printf("  file:        %s\n", acct.URL.Path)
+
+	case "list":
+		accts := ks.Accounts()
+		fmt.Printf("[Keystore/list] dir=%s accounts=%d\n", dir, len(accts))
+		for i, a := range accts {
+			fmt.Printf("  #%d  %s  %s\n", i, a.Address.Hex(), a.URL.Path)
+		}
+
+	default:
+		usage()
+	}
+}
+
+// newPassphrase 读取新口令；从终端输入时要求输两遍
+func newPassphrase() string {
+	if os.Getenv("KEYSTORE_PASS") != "" || os.Getenv("KEYSTORE_PASS_FILE") != "" {
+		pass, err := wallet.Passphrase("")
+		mustOK("read passphrase", err)
+		return pass
+	}
+	pass := readLine("New passphrase: ")
+	if readLine("Repeat passphrase: ") != pass {
+		log.Fatalf("[ERR] passphrases do not match")
+	}
+	if pass == "" {
+		log.Fatalf("[ERR] empty passphrase")
+	}
+	return pass
+}
+
+// readLine 读取一行机密输入（口令 / 私钥）：终端下不回显，管道输入时逐行读取
+func readLine(prompt string) string {
+	pass, err := wallet.ReadPassword(prompt)
+	mustOK("read passphrase", err)
+	return pass
+}
+
+func usage() {
+	log.Fatalf("usage: go run ./16-keystore new | import [keyfile.json] | list")
+}
+
+func mustOK(tag string, err error) {
+	if err != nil {
+		log.Fatalf("[ERR] %s: %v", tag, err)
+	}
+}
+
+func getenv(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"example.com/ethclient-demo/pkg/feeoracle"
+	"example.com/ethclient-demo/pkg/rawtx"
+	"example.com/ethclient-demo/pkg/units"
+	"example.com/ethclient-demo/pkg/wallet"
+)
+
+const (
+	defaultRPC = "https://eth-sepolia.g.alchemy.com/v2/xxx"
+	timeout    = 30 * time.Second
+)
+
+// 离线签名三步走，私钥只出现在第 2 步的离线机器上：
+//
+//	FROM=<addr> TO=<addr> AMOUNT_ETH=0.01 go run ./17-offline-tx build > unsigned.json   联网：查 nonce / 费用 / gas
+//	PRIV_KEY_HEX=<hex> go run ./17-offline-tx sign unsigned.json > signed.txt           离线：签名输出 raw hex
+//	go run ./17-offline-tx broadcast signed.txt                                          联网：解码、确认后广播
+//
+// build 环境变量：RPC_URL、FROM（必填）、TO（不填为部署合约）、AMOUNT_ETH、DATA（0x calldata）、
+// FEE_SPEED（slow/standard/fast）、NONCE / GAS（手动指定时不再查询）。
+// sign 的账户同其它程序：设置 KEYSTORE_DIR 用 keystore，否则用 PRIV_KEY_HEX。
+// broadcast 时 YES=1 跳过确认。build / sign 的结果写到 stdout（或 OUT 指定的文件），说明信息写到 stderr。
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "build":
+		runBuild()
+	case "sign":
+		if len(os.Args) != 3 {
+			usage()
+		}
+		runSign(os.Args[2])
+	case "broadcast":
+		if len(os.Args) != 3 {
+			usage()
+		}
+		runBroadcast(os.Args[2])
+	default:
+		usage()
+	}
+}
+
+func runBuild() {
+	if !common.IsHexAddress(os.Getenv("FROM")) {
+		log.Fatalf("[ERR] FROM must be the signing address, got %q", os.Getenv("FROM"))
+	}
+	from := common.HexToAddress(os.Getenv("FROM"))
+	var to *common.Address
+	if v := os.Getenv("TO"); v != "" {
+		if !common.IsHexAddress(v) {
+			log.Fatalf("[ERR] invalid TO: %s", v)
+		}
+		addr := common.HexToAddress(v)
+		to = &addr
+	}
+	value, err := units.ParseEther(getenv("AMOUNT_ETH", "0"))
+	mustOK("AMOUNT_ETH", err)
+	var data []byte
+	if v := os.Getenv("DATA"); v != "" {
+		data, err = hexutil.Decode(v)
+		mustOK("DATA", err)
+	}
+	speed, err := feeoracle.ParseSpeed(getenv("FEE_SPEED", "standard"))
+	mustOK("FEE_SPEED", err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	client, err := ethclient.DialContext(ctx, getenv("RPC_URL", defaultRPC))
+	mustOK("ethclient.Dial", err)
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	mustOK("ChainID", err)
+	nonce, err := getenvUint("NONCE", func() (uint64, error) { return client.PendingNonceAt(ctx, from) })
+	mustOK("nonce", err)
+	tip, feeCap, err := feeoracle.New(client).Suggest(ctx, speed)
+	mustOK("feeoracle.Suggest", err)
+	gas, err := getenvUint("GAS", func() (uint64, error) {
+		g, err := client.EstimateGas(ctx, ethereum.CallMsg{
+			From: from, To: to, Value: value, Data: data, GasTipCap: tip, GasFeeCap: feeCap,
+		})
+		if len(data) > 0 {
+			g = g * 115 / 100 // 合约调用 / 部署留 15% 余量；纯转账固定 21000 不需要
+		}
+		return g, err
+	})
+	mustOK("gas", err)
+
+	u := &rawtx.Unsigned{
+		ChainID:   chainID,
+		From:      &from,
+		Nonce:     nonce,
+		To:        to,
+		Value:     value,
+		Gas:       gas,
+		GasTipCap: tip,
+		GasFeeCap: feeCap,
+		Data:      data,
+	}
+	mustOK("validate", u.Validate())
+	mustOK("write unsigned tx", u.Save(os.Getenv("OUT")))
+
+	fmt.Fprintln(os.Stderr, "[Tx/build]")
+	fmt.Fprintf(os.Stderr, "  chainId:     %s\n", chainID)
+	fmt.Fprintf(os.Stderr, "  from:        %s\n", from.Hex())
+	fmt.Fprintf(os.Stderr, "  nonce:       %d\n", nonce)
+	fmt.Fprintf(os.Stderr, "  fee speed:   %s\n", speed)
+	fmt.Fprintf(os.Stderr, "  gas:         %d\n", gas)
+	fmt.Fprintln(os.Stderr, "  next:        copy the JSON to the offline machine and run `sign`")
+}
+
+func runSign(path string) {
+	u, err := rawtx.Load(path)
+	mustOK("load unsigned tx", err)
+	tx, err := u.Tx()
+	mustOK("build tx", err)
+
+	key, err := wallet.LoadSigner(os.Getenv("PRIV_KEY_HEX"))
+	if errors.Is(err, wallet.ErrNoKeystore) {
+		log.Fatalf("[ERR] set KEYSTORE_DIR or PRIV_KEY_HEX")
+	}
+	mustOK("load signer", err)
+	if u.From != nil && *u.From != key.Address {
+		log.Fatalf("[ERR] tx is for %s but signer is %s", u.From.Hex(), key.Address.Hex())
+	}
+
+	signed, err := key.SignTx(tx, u.ChainID)
+	mustOK("SignTx", err)
+	raw, err := rawtx.Encode(signed)
+	mustOK("encode tx", err)
+	if out := os.Getenv("OUT"); out != "" && out != "-" {
+		mustOK("write raw tx", os.WriteFile(out, []byte(raw+"\n"), 0o644))
+	} else {
+		fmt.Println(raw)
+	}
+
+	fmt.Fprintln(os.Stderr, "[Tx/sign]")
+	printTx(os.Stderr, signed, key.Address)
+	fmt.Fprintln(os.Stderr, "  next:        copy the raw hex to an online machine and run `broadcast`")
+}
+
+func runBroadcast(arg string) {
+	tx, err := rawtx.ReadArg(arg)
+	mustOK("decode raw tx", err)
+	from, err := rawtx.Sender(tx)
+	mustOK("recover sender", err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	rpcURL := getenv("RPC_URL", defaultRPC)
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	mustOK("ethclient.Dial", err)
+	defer client.Close()
+
+	fmt.Println("[Tx/broadcast]")
+	fmt.Printf("  rpc:         %s\n", rpcURL)
+	printTx(os.Stdout, tx, from)
+
+	// 签名时的 chainId 必须和节点一致，否则节点会拒绝（或者更糟：广播到了另一条链）
+	chainID, err := client.ChainID(ctx)
+	mustOK("ChainID", err)
+	if tx.Protected() && tx.ChainId().Cmp(chainID) != 0 {
+		log.Fatalf("[ERR] tx chainId %s != node chainId %s", tx.ChainId(), chainID)
+	}
+	pending, err := client.PendingNonceAt(ctx, from)
+	mustOK("PendingNonceAt", err)
+	if tx.Nonce() < pending {
+		fmt.Printf("  warning:     nonce %d already used (pending nonce %d), the node will reject it\n", tx.Nonce(), pending)
+	} else if tx.Nonce() > pending {
+		fmt.Printf("  warning:     nonce gap (pending nonce %d), tx will wait in the queue\n", pending)
+	}
+
+	if os.Getenv("YES") != "1" && !confirm("Broadcast this transaction? [y/N] ") {
+		fmt.Println("[Aborted]")
+		return
+	}
+	mustOK("SendTransaction", client.SendTransaction(ctx, tx))
+	fmt.Printf("  tx.hash:     %s\n", tx.Hash().Hex())
+	fmt.Println("[Done] broadcasted")
+}
+
+// printTx 打印交易要点，供签名 / 广播前人工核对
+func printTx(w io.Writer, tx *types.Transaction, from common.Address) {
+	to := "(contract creation)"
+	if tx.To() != nil {
+		to = tx.To().Hex()
+	}
+	fmt.Fprintf(w, "  type:        %d\n", tx.Type())
+	fmt.Fprintf(w, "  chainId:     %s\n", tx.ChainId())
+	fmt.Fprintf(w, "  from:        %s\n", from.Hex())
+	fmt.Fprintf(w, "  to:          %s\n", to)
+	fmt.Fprintf(w, "  value:       %s wei (%s ETH)\n", tx.Value(), units.FormatEther(tx.Value()))
+	fmt.Fprintf(w, "  nonce:       %d\n", tx.Nonce())
+	fmt.Fprintf(w, "  gas:         %d\n", tx.Gas())
+	fmt.Fprintf(w, "  tip:         %s wei\n", tx.GasTipCap())
+	fmt.Fprintf(w, "  maxFee:      %s wei\n", tx.GasFeeCap())
+	fmt.Fprintf(w, "  max cost:    %s ETH (value + gas * maxFee)\n", units.FormatEther(tx.Cost()))
+	if len(tx.Data()) > 0 {
+		fmt.Fprintf(w, "  data:        %d bytes, selector %s\n", len(tx.Data()), hexutil.Encode(tx.Data()[:min(4, len(tx.Data()))]))
+	}
+	fmt.Fprintf(w, "  tx.hash:     %s\n", tx.Hash().Hex())
+}
+
+func confirm(prompt string) bool {
+	fmt.Print(prompt)
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	ans := strings.ToLower(strings.TrimSpace(line))
+	return ans == "y" || ans == "yes"
+}
+
+// getenvUint 读取 k；未设置时调用 fallback 查询
+func getenvUint(k string, fallback func() (uint64, error)) (uint64, error) {
+	v := os.Getenv(k)
+	if v == "" {
+		return fallback()
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", k, v)
+	}
+	return n, nil
+}
+
+func usage() {
+	log.Fatalf("usage: go run ./17-offline-tx build | sign <unsigned.json> | broadcast <rawHex|file>")
+}
+
+func mustOK(tag string, err error) {
+	if err != nil {
+		log.Fatalf("[ERR] %s: %v", tag, err)
+	}
+}
+
+func getenv(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"example.com/ethclient-demo/pkg/abifile"
+	"example.com/ethclient-demo/pkg/calldata"
+	"example.com/ethclient-demo/pkg/eventdec"
+	"example.com/ethclient-demo/pkg/rawtx"
+	"example.com/ethclient-demo/pkg/units"
+)
+
+// 离线解码一笔已签名交易的 raw hex（eth_sendRawTransaction 的参数 / eth_getRawTransactionByHash 的结果），不需要节点：
+//
+//	go run ./18-decode-tx <rawHex|file> [abi.json|abiDir]
+//
+// 支持所有交易类型：legacy(0)、access-list(1)、dynamic-fee(2)、blob(3)、set-code(4)。
+// 发送方用 types.LatestSignerForChainID 从签名恢复；给出 ABI 文件或目录时按 4 字节 selector 解码 calldata。
+func main() {
+	if len(os.Args) < 2 || len(os.Args) > 3 {
+		log.Fatalf("usage: go run ./18-decode-tx <rawHex|file> [abi.json|abiDir]")
+	}
+	tx, err := rawtx.ReadArg(os.Args[1])
+	mustOK("decode raw tx", err)
+	var idx *calldata.Index
+	if len(os.Args) == 3 {
+		idx = loadIndex(os.Args[2])
+	}
+
+	fmt.Println("[Tx/decode]")
+	fmt.Printf("  type:        %d (%s)\n", tx.Type(), typeName(tx.Type()))
+	fmt.Printf("  hash:        %s\n", tx.Hash().Hex())
+	fmt.Printf("  size:        %d bytes\n", tx.Size())
+	if tx.Protected() {
+		fmt.Printf("  chainId:     %s\n", tx.ChainId())
+	} else {
+		fmt.Printf("  chainId:     - (pre-EIP-155, replayable on any chain)\n")
+	}
+	if from, err := rawtx.Sender(tx); err != nil {
+		fmt.Printf("  from:        <recover failed: %v>\n", err)
+	} else {
+		fmt.Printf("  from:        %s\n", from.Hex())
+	}
+	if tx.To() != nil {
+		fmt.Printf("  to:          %s\n", tx.To().Hex())
+	} else {
+		fmt.Printf("  to:          <contract-creation>\n")
+	}
+	fmt.Printf("  nonce:       %d\n", tx.Nonce())
+	fmt.Printf("  value:       %s wei (%s ETH)\n", tx.Value(), units.FormatEther(tx.Value()))
+	fmt.Printf("  gasLimit:    %d\n", tx.Gas())
+
+	// 费用字段：legacy / access-list 只有 gasPrice；其余是 EIP-1559 的 tip + feeCap
+	switch tx.Type() {
+	case types.LegacyTxType, types.AccessListTxType:
+		fmt.Printf("  gasPrice:    %s wei (%s Gwei)\n", tx.GasPrice(), units.FormatGwei(tx.GasPrice()))
+	default:
+		fmt.Printf("  tipCap:      %s wei (%s Gwei)\n", tx.GasTipCap(), units.FormatGwei(tx.GasTipCap()))
+		fmt.Printf("  feeCap:      %s wei (%s Gwei)\n", tx.GasFeeCap(), units.FormatGwei(tx.GasFeeCap()))
+	}
+	if tx.Type() == types.BlobTxType {
+		fmt.Printf("  blobFeeCap:  %s wei (%s Gwei)\n", tx.BlobGasFeeCap(), units.FormatGwei(tx.BlobGasFeeCap()))
+		fmt.Printf("  blobGas:     %d (%d blobs)\n", tx.BlobGas(), len(tx.BlobHashes()))
+		for i, h := range tx.BlobHashes() {
+			fmt.Printf("    blob[%d]:   %s\n", i, h.Hex())
+		}
+		if sc := tx.BlobTxSidecar(); sc != nil {
+			fmt.Printf("  sidecar:     version=%d blobs=%d commitments=%d proofs=%d\n",
+				sc.Version, len(sc.Blobs), len(sc.Commitments), len(sc.Proofs))
+		} else {
+			fmt.Printf("  sidecar:     none (canonical form, as stored in blocks)\n")
+		}
+	}
+	fmt.Printf("  max cost:    %s ETH (value + gas * price [+ blob fee])\n", units.FormatEther(tx.Cost()))
+
+	if al := tx.AccessList(); len(al) > 0 {
+		fmt.Printf("  accessList:  %d addresses, %d storage keys\n", len(al), al.StorageKeys())
+		for _, t := range al {
+			fmt.Printf("    %s\n", t.Address.Hex())
+			for _, k := range t.StorageKeys {
+				fmt.Printf("      %s\n", k.Hex())
+			}
+		}
+	}
+	if auths := tx.SetCodeAuthorizations(); len(auths) > 0 {
+		fmt.Printf("  authorizations: %d\n", len(auths))
+		for i, a := range auths {
+			authority := "<recover failed>"
+			if addr, err := a.Authority(); err == nil {
+				authority = addr.Hex()
+			}
+			fmt.Printf("    #%d authority=%s delegate=%s chainId=%s nonce=%d\n",
+				i, authority, a.Address.Hex(), a.ChainID.Dec(), a.Nonce)
+		}
+	}
+
+	v, r, s := tx.RawSignatureValues()
+	fmt.Printf("  signature:   v=%s r=%s s=%s\n", v, hexutil.EncodeBig(r), hexutil.EncodeBig(s))
+
+	printCalldata(tx, idx)
+}
+
+// loadIndex 把单个 ABI 文件或整个 ABI 目录加载为 selector 索引
+func loadIndex(path string) *calldata.Index {
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		idx, err := calldata.LoadDir(path)
+		mustOK("load abi dir", err)
+		return idx
+	}
+	parsed, err := abifile.Load(path)
+	mustOK("load abi", err)
+	idx := calldata.NewIndex()
+	idx.Add(parsed, path)
+	return idx
+}
+
+// printCalldata 打印 calldata；有 ABI 索引时按 selector 找到方法并解码参数
+func printCalldata(tx *types.Transaction, idx *calldata.Index) {
+	data := tx.Data()
+	if len(data) == 0 {
+		fmt.Printf("  data:        (empty)\n")
+		return
+	}
+	if tx.To() == nil {
+		// 部署交易的 data 是 init code，构造参数拼在字节码之后，长度未知，这里不解码
+		fmt.Printf("  data:        %d bytes of init code\n", len(data))
+		return
+	}
+	if len(data) < 4 {
+		fmt.Printf("  data:        %s (shorter than a selector)\n", hexutil.Encode(data))
+		return
+	}
+	fmt.Printf("  data:        %d bytes, selector %s\n", len(data), hexutil.Encode(data[:4]))
+	if idx == nil {
+		return
+	}
+	res, err := idx.DecodeTx(tx)
+	if err != nil {
+		fmt.Printf("  method:      <%v>\n", err)
+		return
+	}
+	if res.Ambiguous() {
+		fmt.Printf("  ambiguous:   %d signatures decode this calldata\n", len(res.Calls))
+	}
+	for _, c := range res.Calls {
+		fmt.Printf("  method:      %s\n", c.Entry.Method.Sig)
+		for i, name := range c.Names {
+			fmt.Printf("    %-10s %s  // %s\n", name+":", eventdec.FormatArg(c.Entry.Method.Inputs[i].Type, c.Args[name]), c.Entry.Method.Inputs[i].Type)
+		}
+	}
+}
+
+func typeName(t uint8) string {
+	switch t {
+	case types.LegacyTxType:
+		return "legacy"
+	case types.AccessListTxType:
+		return "access-list, EIP-2930"
+	case types.DynamicFeeTxType:
+		return "dynamic-fee, EIP-1559"
+	case types.BlobTxType:
+		return "blob, EIP-4844"
+	case types.SetCodeTxType:
+		return "set-code, EIP-7702"
+	}
+	return "unknown"
+}
+
+func mustOK(tag string, err error) {
+	if err != nil {
+		log.Fatalf("[ERR] %s: %v", tag, err)
+	}
+}
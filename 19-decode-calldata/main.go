@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"example.com/ethclient-demo/pkg/calldata"
+	"example.com/ethclient-demo/pkg/eventdec"
+)
+
+// 用本地 ABI 目录反查 calldata 的方法签名并解码参数（06 / 12 里手工拼 selector 的反向操作）：
+//
+//	go run ./19-decode-calldata <abiDir>                     列出索引统计与 selector 碰撞
+//	go run ./19-decode-calldata <abiDir> <0xcalldata>...     解码一段或多段 calldata
+//
+// abiDir 下的 .json 递归加载，既可以是纯 ABI 数组，也可以是 Hardhat / Foundry 编译产物。
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatalf("usage: go run ./19-decode-calldata <abiDir> [0xcalldata...]")
+	}
+	idx, err := calldata.LoadDir(os.Args[1])
+	mustOK("load abi dir", err)
+
+	fmt.Println("[Calldata/index]")
+	fmt.Printf("  dir:         %s\n", os.Args[1])
+	fmt.Printf("  methods:     %d\n", idx.Len())
+	skipped := make([]string, 0, len(idx.Skipped))
+	for path := range idx.Skipped {
+		skipped = append(skipped, path)
+	}
+	sort.Strings(skipped)
+	for _, path := range skipped {
+		fmt.Printf("  skipped:     %v\n", idx.Skipped[path]) // 错误信息里已带文件名
+	}
+	collisions := idx.Collisions()
+	fmt.Printf("  collisions:  %d\n", len(collisions))
+	for _, sel := range collisions {
+		fmt.Printf("    %s\n", hexutil.Encode(sel[:]))
+		for _, e := range idx.Lookup(sel) {
+			fmt.Printf("      %s  (%s)\n", e.Method.Sig, strings.Join(e.Sources, ", "))
+		}
+	}
+
+	for i, arg := range os.Args[2:] {
+		fmt.Printf("\n#%d\n", i+1)
+		data, err := hexutil.Decode(arg)
+		if err != nil {
+			fmt.Printf("  error:       decode hex: %v\n", err)
+			continue
+		}
+		printResult(idx, data)
+	}
+}
+
+func printResult(idx *calldata.Index, data []byte) {
+	res, err := idx.Decode(data)
+	switch {
+	case errors.Is(err, calldata.ErrShortData):
+		fmt.Printf("  data:        %s (no selector)\n", hexutil.Encode(data))
+		return
+	case err != nil:
+		fmt.Printf("  selector:    %s\n", hexutil.Encode(data[:4]))
+		fmt.Printf("  error:       %v\n", err)
+		return
+	}
+	fmt.Printf("  selector:    %s\n", res.SelectorHex())
+	if res.Ambiguous() {
+		fmt.Printf("  ambiguous:   %d signatures decode this calldata\n", len(res.Calls))
+	}
+	for _, c := range res.Calls {
+		fmt.Printf("  method:      %s  (%s)\n", c.Entry.Method.Sig, strings.Join(c.Entry.Sources, ", "))
+		if !c.Exact {
+			fmt.Printf("  note:        arguments do not re-encode to the same bytes (trailing data or non-canonical encoding)\n")
+		}
+		for j, name := range c.Names {
+			fmt.Printf("    %-10s %s  // %s\n", name+":", eventdec.FormatArg(c.Entry.Method.Inputs[j].Type, c.Args[name]), c.Entry.Method.Inputs[j].Type)
+		}
+	}
+}
+
+func mustOK(tag string, err error) {
+	if err != nil {
+		log.Fatalf("[ERR] %s: %v", tag, err)
+	}
+}
@@ -0,0 +1,43 @@
+// Package abifile 从磁盘加载合约 ABI：既支持 solc/abigen 用的纯 ABI 数组，
+// 也支持 Hardhat / Foundry 编译产物里带 "abi" 字段的 JSON。
+package abifile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// Load 读取并解析 path 指向的 ABI 文件
+func Load(path string) (abi.ABI, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return abi.ABI{}, err
+	}
+	parsed, err := Parse(raw)
+	if err != nil {
+		return abi.ABI{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return parsed, nil
+}
+
+// Parse 解析 ABI JSON；顶层是对象时取其 "abi" 字段
+func Parse(raw []byte) (abi.ABI, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '{' {
+		var artifact struct {
+			ABI json.RawMessage `json:"abi"`
+		}
+		if err := json.Unmarshal(raw, &artifact); err != nil {
+			return abi.ABI{}, err
+		}
+		if len(artifact.ABI) == 0 {
+			return abi.ABI{}, fmt.Errorf("no \"abi\" field in artifact")
+		}
+		raw = artifact.ABI
+	}
+	return abi.JSON(bytes.NewReader(raw))
+}
@@ -0,0 +1,207 @@
+// Package calldata 是手工拼 calldata（keccak 前 4 字节 selector + ABI 编码参数）的反向操作：
+// 把一个目录下的 ABI JSON 建成 selector 索引，再按 calldata 前 4 字节找到方法并解码参数。
+// 4 字节 selector 可能碰撞（不同签名、同一 selector），此时返回全部能成功解码的候选。
+package calldata
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"example.com/ethclient-demo/pkg/abifile"
+	"example.com/ethclient-demo/pkg/eventdec"
+)
+
+var (
+	// ErrShortData：calldata 不足 4 字节，没有 selector（如纯转账）
+	ErrShortData = errors.New("calldata shorter than 4-byte selector")
+	// ErrUnknownSelector：索引里没有该 selector
+	ErrUnknownSelector = errors.New("unknown selector")
+	// ErrNoCandidate：selector 命中，但所有候选方法都无法解码参数
+	ErrNoCandidate = errors.New("no matching method could decode the arguments")
+)
+
+// Entry 是索引中的一个方法签名；同一签名出现在多个 ABI 文件时合并 Sources
+type Entry struct {
+	Method  abi.Method
+	Sources []string
+}
+
+// Index 是 selector → 方法签名 的索引
+type Index struct {
+	entries map[[4]byte][]*Entry
+	// Skipped 记录 LoadDir 时无法解析为 ABI 的 JSON 文件（如 Hardhat 的 .dbg.json）
+	Skipped map[string]error
+}
+
+// NewIndex 返回空索引
+func NewIndex() *Index {
+	return &Index{entries: make(map[[4]byte][]*Entry), Skipped: make(map[string]error)}
+}
+
+// LoadDir 递归加载 dir 下所有 .json 文件（纯 ABI 数组或带 "abi" 字段的编译产物）
+func LoadDir(dir string) (*Index, error) {
+	idx := NewIndex()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".json") {
+			return nil
+		}
+		parsed, err := abifile.Load(path)
+		if err != nil {
+			idx.Skipped[path] = err
+			return nil
+		}
+		rel, relErr := filepath.Rel(dir, path)
+		if relErr != nil {
+			rel = path
+		}
+		idx.Add(parsed, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// Add 把 parsed 中的全部方法加入索引，source 用于标注来源（通常是文件名）
+func (idx *Index) Add(parsed abi.ABI, source string) {
+	for _, m := range parsed.Methods {
+		var sel [4]byte
+		copy(sel[:], m.ID)
+		dup := false
+		for _, e := range idx.entries[sel] {
+			if e.Method.Sig == m.Sig { // 同一签名编码相同，只记来源
+				e.Sources = append(e.Sources, source)
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			idx.entries[sel] = append(idx.entries[sel], &Entry{Method: m, Sources: []string{source}})
+		}
+	}
+}
+
+// Len 返回索引中不同签名的个数
+func (idx *Index) Len() int {
+	n := 0
+	for _, es := range idx.entries {
+		n += len(es)
+	}
+	return n
+}
+
+// Lookup 返回 selector 对应的全部方法签名
+func (idx *Index) Lookup(sel [4]byte) []*Entry {
+	return idx.entries[sel]
+}
+
+// Collisions 返回所有对应多个不同签名的 selector，按 selector 排序
+func (idx *Index) Collisions() [][4]byte {
+	var out [][4]byte
+	for sel, es := range idx.entries {
+		if len(es) > 1 {
+			out = append(out, sel)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return bytes.Compare(out[i][:], out[j][:]) < 0 })
+	return out
+}
+
+// Call 是按某个方法签名解码出的调用
+type Call struct {
+	Entry *Entry
+	Names []string       // 参数名，按 ABI 声明顺序；匿名参数记为 arg0、arg1…
+	Args  map[string]any // 参数名 → Go 值，格式化可用 eventdec.FormatArg / JSONValue
+	Exact bool           // 参数重新编码后与 calldata 完全一致
+}
+
+// Result 是一段 calldata 的解码结果
+type Result struct {
+	Selector [4]byte
+	Calls    []*Call // 能成功解码的候选，通常只有一个
+}
+
+// Ambiguous 报告是否有多个签名都能解码这段 calldata（selector 碰撞且参数布局兼容）
+func (r *Result) Ambiguous() bool { return len(r.Calls) > 1 }
+
+// SelectorHex 返回 0x 开头的 selector
+func (r *Result) SelectorHex() string { return hexutil.Encode(r.Selector[:]) }
+
+// Decode 按 selector 查找方法并解码参数。碰撞时逐个尝试：优先保留参数重新编码后与原 calldata
+// 完全一致的候选；都不一致（如尾部追加了额外字节）时退而保留所有能解码的候选，并标记 Exact=false。
+func (idx *Index) Decode(data []byte) (*Result, error) {
+	if len(data) < 4 {
+		return nil, ErrShortData
+	}
+	var sel [4]byte
+	copy(sel[:], data[:4])
+	entries := idx.entries[sel]
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w %s", ErrUnknownSelector, hexutil.Encode(sel[:]))
+	}
+	var exact, loose []*Call
+	var lastErr error
+	for _, e := range entries {
+		c, err := decodeWith(e, data[4:])
+		switch {
+		case err != nil:
+			lastErr = err
+		case c.Exact:
+			exact = append(exact, c)
+		default:
+			loose = append(loose, c)
+		}
+	}
+	res := &Result{Selector: sel, Calls: exact}
+	if len(exact) == 0 {
+		res.Calls = loose
+	}
+	if len(res.Calls) == 0 {
+		return nil, fmt.Errorf("%w: %v", ErrNoCandidate, lastErr)
+	}
+	return res, nil
+}
+
+// DecodeTx 解码交易的 input；部署交易（To 为空）的 data 是 init code，返回 ErrUnknownSelector
+func (idx *Index) DecodeTx(tx *types.Transaction) (*Result, error) {
+	if tx.To() == nil {
+		return nil, fmt.Errorf("%w: contract creation", ErrUnknownSelector)
+	}
+	return idx.Decode(tx.Data())
+}
+
+// DecodeMsg 解码 eth_call / EstimateGas 用的 CallMsg.Data
+func (idx *Index) DecodeMsg(msg ethereum.CallMsg) (*Result, error) {
+	return idx.Decode(msg.Data)
+}
+
+func decodeWith(e *Entry, payload []byte) (*Call, error) {
+	values, err := e.Method.Inputs.Unpack(payload)
+	if err != nil {
+		return nil, err
+	}
+	c := &Call{Entry: e, Args: make(map[string]any, len(values))}
+	// Unpack 会忽略尾部多余字节、也不检查高位填充；重新编码比对可以区分“碰巧能解出来”的错误签名
+	packed, err := e.Method.Inputs.Pack(values...)
+	c.Exact = err == nil && bytes.Equal(packed, payload)
+	for i, arg := range e.Method.Inputs {
+		name := eventdec.ArgName(arg, i)
+		c.Names = append(c.Names, name)
+		c.Args[name] = values[i]
+	}
+	return c, nil
+}
@@ -0,0 +1,126 @@
+// Package eip712 对 EIP-712 结构化数据（domain / types / primaryType / message 的 JSON 文档）
+// 计算域分隔符、结构体哈希与最终摘要，并签名、恢复签名者。编码规则由 go-ethereum 的 apitypes 实现。
+package eip712
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"example.com/ethclient-demo/pkg/sigutil"
+	"example.com/ethclient-demo/pkg/wallet"
+)
+
+// Hashes 是签名涉及的三个哈希：digest = keccak256(0x19 0x01 ‖ domainSeparator ‖ structHash)
+type Hashes struct {
+	DomainSeparator common.Hash
+	StructHash      common.Hash
+	Digest          common.Hash
+}
+
+// Load 读取 typed-data JSON 文件
+func Load(path string) (*apitypes.TypedData, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	td, err := Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return td, nil
+}
+
+// Parse 解析 typed-data JSON。message 里的数字保留为十进制字符串，避免超过 2^53 的 uint256（如代币数量）被 float64 截断。
+func Parse(raw []byte) (*apitypes.TypedData, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var td apitypes.TypedData
+	if err := dec.Decode(&td); err != nil {
+		return nil, fmt.Errorf("parse typed data: %w", err)
+	}
+	if td.PrimaryType == "" {
+		return nil, errors.New("typed data: primaryType missing")
+	}
+	if _, ok := td.Types["EIP712Domain"]; !ok {
+		return nil, errors.New("typed data: types.EIP712Domain missing")
+	}
+	if _, ok := td.Types[td.PrimaryType]; !ok {
+		return nil, fmt.Errorf("typed data: primaryType %q not in types", td.PrimaryType)
+	}
+	td.Message = numbersToStrings(td.Message).(map[string]interface{})
+	return &td, nil
+}
+
+func numbersToStrings(v interface{}) interface{} {
+	switch x := v.(type) {
+	case json.Number:
+		return x.String()
+	case map[string]interface{}:
+		for k, e := range x {
+			x[k] = numbersToStrings(e)
+		}
+		return x
+	case []interface{}:
+		for i, e := range x {
+			x[i] = numbersToStrings(e)
+		}
+		return x
+	}
+	return v
+}
+
+// Hash 计算域分隔符、primaryType 结构体哈希与最终签名摘要
+func Hash(td *apitypes.TypedData) (*Hashes, error) {
+	domain, err := td.HashStruct("EIP712Domain", td.Domain.Map())
+	if err != nil {
+		return nil, fmt.Errorf("hash domain: %w", err)
+	}
+	msg, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return nil, fmt.Errorf("hash %s: %w", td.PrimaryType, err)
+	}
+	h := &Hashes{
+		DomainSeparator: common.BytesToHash(domain),
+		StructHash:      common.BytesToHash(msg),
+	}
+	h.Digest = crypto.Keccak256Hash([]byte{0x19, 0x01}, domain, msg)
+	return h, nil
+}
+
+// Sign 对 typed data 签名，返回 65 字节 [R || S || V]，V 为 27/28（与 eth_signTypedData_v4 一致）
+func Sign(td *apitypes.TypedData, key *wallet.Signer) ([]byte, *Hashes, error) {
+	h, err := Hash(td)
+	if err != nil {
+		return nil, nil, err
+	}
+	sig, err := key.SignHash(h.Digest.Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+	return sigutil.ToWallet(sig), h, nil
+}
+
+// Recover 从签名恢复签名者地址；V 接受 0/1 或 27/28
+func Recover(td *apitypes.TypedData, sig []byte) (common.Address, error) {
+	h, err := Hash(td)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return sigutil.Recover(h.Digest.Bytes(), sig)
+}
+
+// Verify 报告 sig 是否由 addr 对 td 签出
+func Verify(td *apitypes.TypedData, sig []byte, addr common.Address) (bool, error) {
+	got, err := Recover(td, sig)
+	if err != nil {
+		return false, err
+	}
+	return got == addr, nil
+}
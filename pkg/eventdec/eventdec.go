@@ -0,0 +1,209 @@
+// Package eventdec 按事件 ABI 自身的 Indexed 标记解码日志：
+// indexed 参数从 Topics 里取，其余参数从 Data 里 ABI 解码，最终合并成一张按参数名索引的表。
+package eventdec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrTopicMismatch：日志的 topic0 与事件签名不一致
+var ErrTopicMismatch = errors.New("topic0 does not match event signature")
+
+// Decoded 是一条日志按事件 ABI 解出的结果
+type Decoded struct {
+	Event *abi.Event
+	Names []string       // 参数名，按 ABI 声明顺序；匿名参数记为 arg0、arg1…
+	Args  map[string]any // 参数名 → Go 值（bytes32 为 [32]byte、uintN 为 *big.Int 等）
+}
+
+// ArgName 返回第 i 个参数在 Args 里的键名
+func ArgName(arg abi.Argument, i int) string {
+	if arg.Name != "" {
+		return arg.Name
+	}
+	return fmt.Sprintf("arg%d", i)
+}
+
+// IsHashedTopic 判断 indexed 参数在 topic 里存的是否是 keccak 哈希（无法还原原值）
+func IsHashedTopic(t abi.Type) bool {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return true
+	}
+	return false
+}
+
+// DecodeLog 用 ev 解码 lg。
+// 非匿名事件要求 Topics[0] == ev.ID；indexed 的动态类型（string/bytes/数组/结构体）在 topic 里
+// 只剩 keccak 哈希，此时对应值为 common.Hash。
+func DecodeLog(ev *abi.Event, lg types.Log) (*Decoded, error) {
+	topics := lg.Topics
+	if !ev.Anonymous {
+		if len(topics) == 0 || topics[0] != ev.ID {
+			return nil, ErrTopicMismatch
+		}
+		topics = topics[1:]
+	}
+
+	indexed := 0
+	for _, arg := range ev.Inputs {
+		if arg.Indexed {
+			indexed++
+		}
+	}
+	if indexed != len(topics) {
+		return nil, fmt.Errorf("%s: expect %d indexed topics, got %d", ev.Name, indexed, len(topics))
+	}
+
+	values, err := ev.Inputs.NonIndexed().UnpackValues(lg.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: unpack data: %w", ev.Name, err)
+	}
+
+	out := &Decoded{Event: ev, Names: make([]string, 0, len(ev.Inputs)), Args: make(map[string]any, len(ev.Inputs))}
+	ti, di := 0, 0
+	for i, arg := range ev.Inputs {
+		name := ArgName(arg, i)
+		out.Names = append(out.Names, name)
+		if !arg.Indexed {
+			out.Args[name] = values[di]
+			di++
+			continue
+		}
+		topic := topics[ti]
+		ti++
+		if IsHashedTopic(arg.Type) {
+			out.Args[name] = topic
+			continue
+		}
+		// 静态类型：借用 abi.ParseTopicsIntoMap 还原成对应的 Go 类型
+		one := arg
+		one.Name = name
+		tmp := make(map[string]any, 1)
+		if err := abi.ParseTopicsIntoMap(tmp, abi.Arguments{one}, []common.Hash{topic}); err != nil {
+			return nil, fmt.Errorf("%s: parse topic %s: %w", ev.Name, name, err)
+		}
+		out.Args[name] = tmp[name]
+	}
+	return out, nil
+}
+
+// Decoder 持有一份合约 ABI，按 topic0 在所有事件里查找匹配项再解码
+type Decoder struct {
+	abi    abi.ABI
+	events map[common.Hash]*abi.Event
+}
+
+// ErrUnknownEvent：ABI 里没有与 topic0 对应的事件（或日志没有 topic）
+var ErrUnknownEvent = errors.New("unknown event")
+
+// NewDecoder 为 ABI 中所有非匿名事件建立 topic0 索引
+func NewDecoder(parsed abi.ABI) *Decoder {
+	d := &Decoder{abi: parsed, events: make(map[common.Hash]*abi.Event, len(parsed.Events))}
+	for name := range parsed.Events {
+		ev := parsed.Events[name]
+		if ev.Anonymous {
+			continue // 匿名事件没有 topic0，无法按签名匹配
+		}
+		d.events[ev.ID] = &ev
+	}
+	return d
+}
+
+// Decode 按 topic0 匹配事件并解码；匹配不到返回 ErrUnknownEvent
+func (d *Decoder) Decode(lg types.Log) (*Decoded, error) {
+	if len(lg.Topics) == 0 {
+		return nil, ErrUnknownEvent
+	}
+	ev, ok := d.events[lg.Topics[0]]
+	if !ok {
+		return nil, ErrUnknownEvent
+	}
+	return DecodeLog(ev, lg)
+}
+
+// FormatArg 按 ABI 类型 t 把解码出的参数转成便于阅读的字符串：bytes / bytesN 为 0x 十六进制，
+// 数组/结构体输出为 JSON，其余走 %v
+func FormatArg(t abi.Type, v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case common.Hash:
+		return x.Hex()
+	case common.Address:
+		return x.Hex()
+	case *big.Int:
+		return x.String()
+	}
+	switch jv := JSONValue(t, v).(type) {
+	case string:
+		return jv
+	case []any, map[string]any:
+		raw, err := json.Marshal(jv)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(raw)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// JSONValue 按 ABI 类型 t 把解码出的 Go 值 v 转换成适合 json.Marshal 的形式：
+// bytes / bytesN → 0x 十六进制，*big.Int → 十进制字符串（避免 JS 精度丢失），
+// 数组/切片逐个递归（uint8[] 在 Go 里同样是 []byte，也按数组输出），结构体（tuple）按 json tag 转成对象。
+// indexed 动态类型在 topic 里只剩哈希，此时 v 是 common.Hash，输出十六进制。
+func JSONValue(t abi.Type, v any) any {
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case common.Address:
+		return x.Hex()
+	case common.Hash:
+		return x.Hex()
+	case *big.Int:
+		return x.String()
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		return JSONValue(t, rv.Elem().Interface())
+	}
+	isList := rv.Kind() == reflect.Array || rv.Kind() == reflect.Slice
+	switch {
+	case isList && (t.T == abi.BytesTy || t.T == abi.FixedBytesTy || t.T == abi.FunctionTy):
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		return hexutil.Encode(b)
+	case isList && (t.T == abi.SliceTy || t.T == abi.ArrayTy):
+		out := make([]any, rv.Len())
+		for i := range out {
+			out[i] = JSONValue(*t.Elem, rv.Index(i).Interface())
+		}
+		return out
+	case rv.Kind() == reflect.Struct && t.T == abi.TupleTy && rv.NumField() == len(t.TupleElems):
+		out := make(map[string]any, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			f := rv.Type().Field(i)
+			name := f.Tag.Get("json")
+			if name == "" {
+				name = f.Name
+			}
+			out[name] = JSONValue(*t.TupleElems[i], rv.Field(i).Interface())
+		}
+		return out
+	}
+	return v
+}
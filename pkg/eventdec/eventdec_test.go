@@ -0,0 +1,154 @@
+package eventdec
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 与 10-deploy-contract 的 Store 合约一致：ItemSet(bytes32,bytes32) 两个参数都不是 indexed
+const storeEvents = `[
+{"anonymous":false,"inputs":[{"indexed":false,"name":"key","type":"bytes32"},{"indexed":false,"name":"value","type":"bytes32"}],"name":"ItemSet","type":"event"}]`
+
+// 同名事件的 indexed 变体，以及带 indexed string 的事件
+const indexedEvents = `[
+{"anonymous":false,"inputs":[{"indexed":true,"name":"key","type":"bytes32"},{"indexed":false,"name":"value","type":"bytes32"}],"name":"ItemSet","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"label","type":"string"},{"indexed":true,"name":"owner","type":"address"},{"indexed":false,"name":"","type":"uint256"},{"indexed":false,"name":"note","type":"string"}],"name":"Labeled","type":"event"}]`
+
+func mustABI(t *testing.T, s string) abi.ABI {
+	t.Helper()
+	a, err := abi.JSON(strings.NewReader(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func word(s string) [32]byte {
+	var b [32]byte
+	copy(b[:], s)
+	return b
+}
+
+func TestDecodeNonIndexed(t *testing.T) {
+	ev := mustABI(t, storeEvents).Events["ItemSet"]
+	data, err := ev.Inputs.Pack(word("foo"), word("bar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := DecodeLog(&ev, types.Log{Topics: []common.Hash{ev.ID}, Data: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Args["key"].([32]byte); got != word("foo") {
+		t.Errorf("key = %x", got)
+	}
+	if got := d.Args["value"].([32]byte); got != word("bar") {
+		t.Errorf("value = %x", got)
+	}
+	if strings.Join(d.Names, ",") != "key,value" {
+		t.Errorf("names = %v", d.Names)
+	}
+}
+
+func TestDecodeIndexed(t *testing.T) {
+	parsed := mustABI(t, indexedEvents)
+
+	ev := parsed.Events["ItemSet"]
+	data, err := ev.Inputs.NonIndexed().Pack(word("bar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := DecodeLog(&ev, types.Log{Topics: []common.Hash{ev.ID, word("foo")}, Data: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Args["key"].([32]byte); got != word("foo") {
+		t.Errorf("key = %x", got)
+	}
+	if got := d.Args["value"].([32]byte); got != word("bar") {
+		t.Errorf("value = %x", got)
+	}
+
+	// indexed string 在 topic 里只剩哈希；匿名参数按位置命名
+	ev = parsed.Events["Labeled"]
+	owner := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	data, err = ev.Inputs.NonIndexed().Pack(big.NewInt(42), "hi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	label := crypto.Keccak256Hash([]byte("hello"))
+	lg := types.Log{Topics: []common.Hash{ev.ID, label, common.BytesToHash(owner.Bytes())}, Data: data}
+	d, err = NewDecoder(parsed).Decode(lg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Event.Name != "Labeled" {
+		t.Fatalf("event = %s", d.Event.Name)
+	}
+	if got := d.Args["label"].(common.Hash); got != label {
+		t.Errorf("label = %s, want topic hash %s", got.Hex(), label.Hex())
+	}
+	if got := d.Args["owner"].(common.Address); got != owner {
+		t.Errorf("owner = %s", got.Hex())
+	}
+	if got := d.Args["arg2"].(*big.Int); got.Int64() != 42 {
+		t.Errorf("arg2 = %v", got)
+	}
+	if got := d.Args["note"].(string); got != "hi" {
+		t.Errorf("note = %q", got)
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	plain := mustABI(t, storeEvents).Events["ItemSet"]
+	indexed := mustABI(t, indexedEvents).Events["ItemSet"]
+	data, _ := plain.Inputs.Pack(word("foo"), word("bar"))
+
+	// 签名相同但 indexed 个数不同：topic 数与 ABI 不符必须报错，而不是错位解码
+	if _, err := DecodeLog(&indexed, types.Log{Topics: []common.Hash{indexed.ID}, Data: data}); err == nil {
+		t.Error("missing indexed topic accepted")
+	}
+	if _, err := DecodeLog(&plain, types.Log{Topics: []common.Hash{plain.ID, word("foo")}, Data: data}); err == nil {
+		t.Error("extra topic accepted")
+	}
+	if _, err := DecodeLog(&plain, types.Log{Topics: []common.Hash{word("x")}, Data: data}); !errors.Is(err, ErrTopicMismatch) {
+		t.Errorf("wrong topic0: err = %v", err)
+	}
+	if _, err := NewDecoder(mustABI(t, storeEvents)).Decode(types.Log{Data: data}); !errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("no topics: err = %v", err)
+	}
+}
+
+func TestJSONValueByType(t *testing.T) {
+	// bytes / bytes3 与 uint8[] / uint8[3] 在 Go 里都是字节切片 / 数组，只能靠 ABI 类型区分
+	const bytesEvent = `[{"anonymous":false,"inputs":[
+{"indexed":false,"name":"raw","type":"bytes"},{"indexed":false,"name":"tag","type":"bytes3"},
+{"indexed":false,"name":"list","type":"uint8[]"},{"indexed":false,"name":"fixed","type":"uint8[3]"}],"name":"Bytes","type":"event"}]`
+	ev := mustABI(t, bytesEvent).Events["Bytes"]
+	data, err := ev.Inputs.Pack([]byte{1, 2}, [3]byte{3, 4, 5}, []uint8{6, 7}, [3]uint8{8, 9, 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := DecodeLog(&ev, types.Log{Topics: []common.Hash{ev.ID}, Data: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j, want := range []string{`"0x0102"`, `"0x030405"`, `[6,7]`, `[8,9,10]`} {
+		name := d.Names[j]
+		raw, err := json.Marshal(JSONValue(ev.Inputs[j].Type, d.Args[name]))
+		if err != nil || string(raw) != want {
+			t.Errorf("%s: JSON = %s, %v, want %s", name, raw, err, want)
+		}
+		if got := FormatArg(ev.Inputs[j].Type, d.Args[name]); got != strings.Trim(want, `"`) {
+			t.Errorf("%s: FormatArg = %s, want %s", name, got, strings.Trim(want, `"`))
+		}
+	}
+}
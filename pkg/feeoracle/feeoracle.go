@@ -0,0 +1,204 @@
+// Package feeoracle 基于 eth_feeHistory 给出 EIP-1559 费用建议。
+//
+// 小费：取最近若干区块中各区块第 10 / 50 / 90 百分位的实付小费，对应 慢 / 标准 / 快 三档，跨区块取中位数；
+// 上限：下一个区块的 baseFee 按“连续满块”最坏情况往后推 Headroom 个区块，再加上小费。
+// 计算部分（FromHistory / Estimate.Fees / NextBaseFee）不依赖节点，可直接喂固定的 FeeHistory 数据验证。
+package feeoracle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Speed 是打包速度档位
+type Speed int
+
+const (
+	Slow Speed = iota
+	Standard
+	Fast
+)
+
+func (s Speed) String() string {
+	switch s {
+	case Slow:
+		return "slow"
+	case Standard:
+		return "standard"
+	case Fast:
+		return "fast"
+	}
+	return fmt.Sprintf("speed(%d)", int(s))
+}
+
+// ParseSpeed 解析 "slow" / "standard" / "fast"
+func ParseSpeed(s string) (Speed, error) {
+	for _, sp := range []Speed{Slow, Standard, Fast} {
+		if sp.String() == s {
+			return sp, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown fee speed %q (want slow/standard/fast)", s)
+}
+
+// defaultHeadroom：baseFee 每块最多涨 12.5%，连续 6 个满块约为 2 倍
+const defaultHeadroom = 6
+
+// percentiles 与 Slow / Standard / Fast 一一对应
+var percentiles = []float64{10, 50, 90}
+
+// ErrNoHistory 表示节点返回的 FeeHistory 为空（或不是 1559 链）
+var ErrNoHistory = errors.New("feeoracle: empty fee history")
+
+// HistoryClient 是 Oracle 需要的节点能力；*ethclient.Client 满足
+type HistoryClient interface {
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+}
+
+// Oracle 查询 FeeHistory 并给出 (tipCap, feeCap)
+type Oracle struct {
+	client HistoryClient
+
+	Blocks   uint64   // 参考最近多少个区块，默认 20
+	Headroom int      // feeCap 按 baseFee 连续上涨多少个区块预留，默认 6（约 2 倍）
+	MinTip   *big.Int // 小费下限；近期区块没有任何交易（测试网常见）时也用它兜底，默认 0.1 Gwei
+}
+
+// New 创建使用默认参数的 Oracle
+func New(c HistoryClient) *Oracle {
+	return &Oracle{
+		client:   c,
+		Blocks:   20,
+		Headroom: defaultHeadroom,
+		MinTip:   big.NewInt(params.GWei / 10),
+	}
+}
+
+// Estimate 是一次 FeeHistory 计算出的费用参考
+type Estimate struct {
+	LastBlock   uint64
+	BaseFee     *big.Int   // 最新区块的 baseFee
+	NextBaseFee *big.Int   // 下一个区块的 baseFee（节点给出；缺失时按 EIP-1559 推算）
+	Tips        []*big.Int // 按 Speed 索引：Slow / Standard / Fast
+	Headroom    int
+}
+
+// Estimate 拉取最近 Blocks 个区块的 FeeHistory 并计算
+func (o *Oracle) Estimate(ctx context.Context) (*Estimate, error) {
+	h, err := o.client.FeeHistory(ctx, o.Blocks, nil, percentiles)
+	if err != nil {
+		return nil, fmt.Errorf("fee history: %w", err)
+	}
+	e, err := FromHistory(h, o.MinTip)
+	if err != nil {
+		return nil, err
+	}
+	e.Headroom = o.Headroom
+	return e, nil
+}
+
+// Suggest 是 Estimate + Fees 的快捷方式
+func (o *Oracle) Suggest(ctx context.Context, speed Speed) (tipCap, feeCap *big.Int, err error) {
+	e, err := o.Estimate(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	tipCap, feeCap = e.Fees(speed)
+	return tipCap, feeCap, nil
+}
+
+// FromHistory 从 FeeHistory（Reward 需按 10/50/90 百分位请求）计算费用参考；minTip 可为 nil
+func FromHistory(h *ethereum.FeeHistory, minTip *big.Int) (*Estimate, error) {
+	if h == nil || len(h.BaseFee) == 0 || len(h.GasUsedRatio) == 0 {
+		return nil, ErrNoHistory
+	}
+	e := &Estimate{Headroom: defaultHeadroom}
+	blocks := len(h.GasUsedRatio)
+	e.LastBlock = h.OldestBlock.Uint64() + uint64(blocks) - 1
+	e.BaseFee = new(big.Int).Set(h.BaseFee[blocks-1])
+	if len(h.BaseFee) > blocks {
+		// feeHistory 的 baseFee 比区块数多一项，最后一项就是下一个区块的 baseFee
+		e.NextBaseFee = new(big.Int).Set(h.BaseFee[blocks])
+	} else {
+		e.NextBaseFee = NextBaseFee(e.BaseFee, h.GasUsedRatio[blocks-1])
+	}
+
+	for i := range percentiles {
+		var samples []*big.Int
+		for b, rewards := range h.Reward {
+			// 空块的百分位小费全是 0，不能算进去，否则安静的测试网会把小费压到 0
+			if b < len(h.GasUsedRatio) && h.GasUsedRatio[b] == 0 {
+				continue
+			}
+			if i < len(rewards) && rewards[i] != nil {
+				samples = append(samples, rewards[i])
+			}
+		}
+		tip := median(samples)
+		if tip == nil || (minTip != nil && tip.Cmp(minTip) < 0) {
+			tip = minTip
+		}
+		if tip == nil {
+			tip = new(big.Int)
+		}
+		e.Tips = append(e.Tips, new(big.Int).Set(tip))
+	}
+	// 保证档位单调：慢 <= 标准 <= 快
+	for i := 1; i < len(e.Tips); i++ {
+		if e.Tips[i].Cmp(e.Tips[i-1]) < 0 {
+			e.Tips[i].Set(e.Tips[i-1])
+		}
+	}
+	return e, nil
+}
+
+// Fees 返回某一档位的 (tipCap, feeCap)：feeCap = NextBaseFee 连续满块上涨 Headroom 次后的值 + tipCap
+func (e *Estimate) Fees(speed Speed) (tipCap, feeCap *big.Int) {
+	if speed < Slow || int(speed) >= len(e.Tips) {
+		speed = Standard
+	}
+	tipCap = new(big.Int).Set(e.Tips[speed])
+	base := new(big.Int).Set(e.NextBaseFee)
+	for range e.Headroom {
+		base = NextBaseFee(base, 1)
+	}
+	return tipCap, base.Add(base, tipCap)
+}
+
+// NextBaseFee 按 EIP-1559 规则由父区块 baseFee 与 gasUsed/gasLimit 比例推算下一个区块的 baseFee：
+// 目标用量为 gasLimit 的一半，每个区块最多涨跌 1/8。
+func NextBaseFee(baseFee *big.Int, gasUsedRatio float64) *big.Int {
+	const (
+		elasticity  = params.DefaultElasticityMultiplier
+		denominator = params.DefaultBaseFeeChangeDenominator
+	)
+	// delta = baseFee * (ratio*elasticity - 1) / denominator，用 1e6 精度的整数运算
+	const scale = 1_000_000
+	diff := int64(gasUsedRatio*elasticity*scale) - scale
+	delta := new(big.Int).Mul(baseFee, big.NewInt(diff))
+	delta.Quo(delta, big.NewInt(scale*denominator))
+	if diff > 0 && delta.Sign() == 0 {
+		delta.SetInt64(1) // 超过目标时至少涨 1 wei，与共识规则一致
+	}
+	next := new(big.Int).Add(baseFee, delta)
+	if next.Sign() < 0 {
+		next.SetInt64(0)
+	}
+	return next
+}
+
+// median 返回中位数（偶数个取偏低的那个）；空切片返回 nil
+func median(xs []*big.Int) *big.Int {
+	if len(xs) == 0 {
+		return nil
+	}
+	s := slices.Clone(xs)
+	slices.SortFunc(s, func(a, b *big.Int) int { return a.Cmp(b) })
+	return s[(len(s)-1)/2]
+}
@@ -0,0 +1,173 @@
+package feeoracle
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func gwei(n float64) *big.Int {
+	v, _ := new(big.Float).Mul(big.NewFloat(n), big.NewFloat(params.GWei)).Int(nil)
+	return v
+}
+
+func gweis(ns ...float64) []*big.Int {
+	out := make([]*big.Int, len(ns))
+	for i, n := range ns {
+		out[i] = gwei(n)
+	}
+	return out
+}
+
+// cannedHistory：5 个区块，第 3 块是空块（百分位小费全 0，不应参与统计）
+func cannedHistory() *ethereum.FeeHistory {
+	return &ethereum.FeeHistory{
+		OldestBlock:  big.NewInt(1000),
+		BaseFee:      gweis(100, 110, 120, 130, 140, 150), // 最后一项是下一个区块的 baseFee
+		GasUsedRatio: []float64{0.5, 0.9, 0, 0.7, 1.0},
+		Reward: [][]*big.Int{
+			gweis(1, 2, 3),
+			gweis(2, 3, 5),
+			gweis(0, 0, 0),
+			gweis(1, 4, 8),
+			gweis(3, 5, 9),
+		},
+	}
+}
+
+type fakeHistory struct {
+	h           *ethereum.FeeHistory
+	err         error
+	blocks      uint64
+	percentiles []float64
+}
+
+func (f *fakeHistory) FeeHistory(ctx context.Context, n uint64, last *big.Int, p []float64) (*ethereum.FeeHistory, error) {
+	f.blocks, f.percentiles = n, p
+	return f.h, f.err
+}
+
+func TestOracleCanned(t *testing.T) {
+	fake := &fakeHistory{h: cannedHistory()}
+	o := New(fake)
+	e, err := o.Estimate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fake.blocks != 20 || !slices.Equal(fake.percentiles, []float64{10, 50, 90}) {
+		t.Errorf("requested blocks=%d percentiles=%v", fake.blocks, fake.percentiles)
+	}
+	if e.LastBlock != 1004 {
+		t.Errorf("LastBlock = %d, want 1004", e.LastBlock)
+	}
+	if e.BaseFee.Cmp(gwei(140)) != 0 || e.NextBaseFee.Cmp(gwei(150)) != 0 {
+		t.Errorf("baseFee = %v next = %v", e.BaseFee, e.NextBaseFee)
+	}
+
+	// 非空块的样本：慢 {1,2,1,3} 标准 {2,3,4,5} 快 {3,5,8,9}，中位数取偏低的一个
+	for speed, want := range map[Speed]*big.Int{Slow: gwei(1), Standard: gwei(3), Fast: gwei(5)} {
+		tip, feeCap := e.Fees(speed)
+		if tip.Cmp(want) != 0 {
+			t.Errorf("%s tip = %v, want %v", speed, tip, want)
+		}
+		// 150 Gwei 连续 6 个满块（每块 +1/8，向下取整）= 304092979429 wei，再加小费
+		wantCap := new(big.Int).Add(big.NewInt(304_092_979_429), want)
+		if feeCap.Cmp(wantCap) != 0 {
+			t.Errorf("%s feeCap = %v, want %v", speed, feeCap, wantCap)
+		}
+		if feeCap.Cmp(tip) < 0 {
+			t.Errorf("%s feeCap %v < tip %v", speed, feeCap, tip)
+		}
+	}
+
+	tip, feeCap, err := o.Suggest(context.Background(), Fast)
+	if err != nil || tip.Cmp(gwei(5)) != 0 || feeCap.Cmp(big.NewInt(309_092_979_429)) != 0 {
+		t.Errorf("Suggest(fast) = %v, %v, %v", tip, feeCap, err)
+	}
+
+	fake.err = errors.New("boom")
+	if _, _, err := o.Suggest(context.Background(), Fast); err == nil {
+		t.Error("client error not returned")
+	}
+}
+
+func TestNextBaseFeeProjection(t *testing.T) {
+	// 节点没给出下一块 baseFee 时按最新区块的 gasUsedRatio 推算
+	h := cannedHistory()
+	h.BaseFee = h.BaseFee[:5]
+	e, err := FromHistory(h, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := gwei(157.5); e.NextBaseFee.Cmp(want) != 0 {
+		t.Errorf("NextBaseFee = %v, want %v", e.NextBaseFee, want)
+	}
+
+	for _, c := range []struct {
+		base  *big.Int
+		ratio float64
+		want  *big.Int
+	}{
+		{gwei(100), 0.5, gwei(100)},   // 恰好目标用量：不变
+		{gwei(100), 1.0, gwei(112.5)}, // 满块：+12.5%
+		{gwei(100), 0, gwei(87.5)},    // 空块：-12.5%
+		{gwei(100), 0.75, gwei(106.25)},
+		{big.NewInt(7), 0.6, big.NewInt(8)}, // 超过目标时至少涨 1 wei
+		{big.NewInt(0), 0, big.NewInt(0)},
+	} {
+		if got := NextBaseFee(c.base, c.ratio); got.Cmp(c.want) != 0 {
+			t.Errorf("NextBaseFee(%v, %v) = %v, want %v", c.base, c.ratio, got, c.want)
+		}
+	}
+}
+
+func TestEmptyRewards(t *testing.T) {
+	minTip := gwei(0.1)
+
+	// 非 1559 节点 / 不返回 reward：三档都退回 minTip
+	h := cannedHistory()
+	h.Reward = nil
+	e, err := FromHistory(h, minTip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for speed := Slow; speed <= Fast; speed++ {
+		tip, feeCap := e.Fees(speed)
+		if tip.Cmp(minTip) != 0 || feeCap.Cmp(tip) < 0 {
+			t.Errorf("no rewards, %s = %v / %v", speed, tip, feeCap)
+		}
+	}
+
+	// 全是空块：样本全部跳过，同样退回 minTip；minTip 为 nil 时为 0
+	h = cannedHistory()
+	h.GasUsedRatio = []float64{0, 0, 0, 0, 0}
+	for _, c := range []struct{ min, want *big.Int }{{minTip, minTip}, {nil, new(big.Int)}} {
+		e, err := FromHistory(h, c.min)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tip, _ := e.Fees(Fast); tip.Cmp(c.want) != 0 {
+			t.Errorf("empty blocks, minTip %v: tip = %v, want %v", c.min, tip, c.want)
+		}
+	}
+
+	// 小费低于下限时抬到下限，且档位保持单调
+	h = cannedHistory()
+	e, _ = FromHistory(h, gwei(4))
+	for speed, want := range map[Speed]*big.Int{Slow: gwei(4), Standard: gwei(4), Fast: gwei(5)} {
+		if tip, _ := e.Fees(speed); tip.Cmp(want) != 0 {
+			t.Errorf("minTip 4 Gwei, %s tip = %v, want %v", speed, tip, want)
+		}
+	}
+
+	for _, h := range []*ethereum.FeeHistory{nil, {OldestBlock: big.NewInt(1)}} {
+		if _, err := FromHistory(h, minTip); !errors.Is(err, ErrNoHistory) {
+			t.Errorf("FromHistory(%v) err = %v, want ErrNoHistory", h, err)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package hdwallet
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// DefaultBasePath 是以太坊账户的 BIP-44 基础路径，第 i 个账户为 m/44'/60'/0'/0/i
+const DefaultBasePath = "m/44'/60'/0'/0"
+
+// HardenedOffset：索引 >= 2^31 为强化派生（路径里写作 i'）
+const HardenedOffset = 0x80000000
+
+var (
+	// ErrInvalidKey 对应 BIP-32 中 IL >= n 或子私钥为 0 的情况（概率约 2^-127），应换下一个索引
+	ErrInvalidKey = errors.New("hdwallet: derived key invalid, use the next index")
+	// ErrHardenedFromPublic 表示试图从扩展公钥做强化派生
+	ErrHardenedFromPublic = errors.New("hdwallet: cannot derive hardened child from public key")
+)
+
+// 序列化版本号（主网 xprv / xpub）
+var (
+	versionPrivate = [4]byte{0x04, 0x88, 0xad, 0xe4}
+	versionPublic  = [4]byte{0x04, 0x88, 0xb2, 0x1e}
+)
+
+// Key 是 BIP-32 扩展密钥；priv 为 nil 时是只含公钥的扩展公钥
+type Key struct {
+	priv      *big.Int
+	pub       []byte // 33 字节压缩公钥
+	chainCode []byte
+	depth     byte
+	parentFP  [4]byte
+	index     uint32
+}
+
+// NewMaster 由种子（16 ~ 64 字节）生成主密钥：I = HMAC-SHA512("Bitcoin seed", seed)
+func NewMaster(seed []byte) (*Key, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("hdwallet: seed length %d not in [16, 64]", len(seed))
+	}
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	I := mac.Sum(nil)
+	k := new(big.Int).SetBytes(I[:32])
+	if k.Sign() == 0 || k.Cmp(crypto.S256().Params().N) >= 0 {
+		return nil, ErrInvalidKey
+	}
+	return newPrivate(k, I[32:], 0, [4]byte{}, 0), nil
+}
+
+func newPrivate(k *big.Int, chainCode []byte, depth byte, parentFP [4]byte, index uint32) *Key {
+	x, y := crypto.S256().ScalarBaseMult(k.FillBytes(make([]byte, 32)))
+	pub := crypto.CompressPubkey(&ecdsa.PublicKey{Curve: crypto.S256(), X: x, Y: y})
+	return &Key{priv: k, pub: pub, chainCode: chainCode, depth: depth, parentFP: parentFP, index: index}
+}
+
+// Child 派生第 i 个子密钥（i >= HardenedOffset 为强化派生）
+func (k *Key) Child(i uint32) (*Key, error) {
+	var data []byte
+	if i >= HardenedOffset {
+		if k.priv == nil {
+			return nil, ErrHardenedFromPublic
+		}
+		data = append([]byte{0}, k.priv.FillBytes(make([]byte, 32))...)
+	} else {
+		data = append([]byte{}, k.pub...)
+	}
+	data = binary.BigEndian.AppendUint32(data, i)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	I := mac.Sum(nil)
+	il := new(big.Int).SetBytes(I[:32])
+	n := crypto.S256().Params().N
+	if il.Cmp(n) >= 0 {
+		return nil, ErrInvalidKey
+	}
+	fp := k.Fingerprint()
+
+	if k.priv != nil {
+		// 子私钥 = (IL + k) mod n
+		child := new(big.Int).Add(il, k.priv)
+		child.Mod(child, n)
+		if child.Sign() == 0 {
+			return nil, ErrInvalidKey
+		}
+		return newPrivate(child, I[32:], k.depth+1, fp, i), nil
+	}
+
+	// 子公钥 = point(IL) + K
+	curve := crypto.S256()
+	px, py := crypto.S256().ScalarBaseMult(I[:32])
+	parent, err := crypto.DecompressPubkey(k.pub)
+	if err != nil {
+		return nil, err
+	}
+	x, y := curve.Add(px, py, parent.X, parent.Y)
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return nil, ErrInvalidKey
+	}
+	pub := crypto.CompressPubkey(&ecdsa.PublicKey{Curve: curve, X: x, Y: y})
+	return &Key{pub: pub, chainCode: I[32:], depth: k.depth + 1, parentFP: fp, index: i}, nil
+}
+
+// Derive 按路径派生，如 "m/44'/60'/0'/0/0"；路径语法同 accounts.ParseDerivationPath（必须从 m 开始）
+func (k *Key) Derive(path string) (*Key, error) {
+	p, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	cur := k
+	for _, i := range p {
+		if cur, err = cur.Child(i); err != nil {
+			return nil, fmt.Errorf("derive %s: %w", path, err)
+		}
+	}
+	return cur, nil
+}
+
+// Neuter 返回对应的扩展公钥（去掉私钥）
+func (k *Key) Neuter() *Key {
+	n := *k
+	n.priv = nil
+	return &n
+}
+
+// IsPrivate 报告是否包含私钥
+func (k *Key) IsPrivate() bool { return k.priv != nil }
+
+// Fingerprint 是 hash160(公钥) 的前 4 字节，子密钥用它标识父密钥
+func (k *Key) Fingerprint() [4]byte {
+	sha := sha256.Sum256(k.pub)
+	h := ripemd160.New()
+	h.Write(sha[:])
+	var fp [4]byte
+	copy(fp[:], h.Sum(nil))
+	return fp
+}
+
+// PrivateKey 返回 secp256k1 私钥；扩展公钥返回错误
+func (k *Key) PrivateKey() (*ecdsa.PrivateKey, error) {
+	if k.priv == nil {
+		return nil, errors.New("hdwallet: public-only key")
+	}
+	return crypto.ToECDSA(k.priv.FillBytes(make([]byte, 32)))
+}
+
+// PublicKey 返回 secp256k1 公钥
+func (k *Key) PublicKey() *ecdsa.PublicKey {
+	pub, _ := crypto.DecompressPubkey(k.pub) // pub 由本包计算，必然合法
+	return pub
+}
+
+// Address 返回以太坊地址
+func (k *Key) Address() common.Address {
+	return crypto.PubkeyToAddress(*k.PublicKey())
+}
+
+// String 序列化为 base58check 的 xprv / xpub（BIP-32 “Serialization format”）
+func (k *Key) String() string {
+	buf := make([]byte, 0, 82)
+	if k.priv != nil {
+		buf = append(buf, versionPrivate[:]...)
+	} else {
+		buf = append(buf, versionPublic[:]...)
+	}
+	buf = append(buf, k.depth)
+	buf = append(buf, k.parentFP[:]...)
+	buf = binary.BigEndian.AppendUint32(buf, k.index)
+	buf = append(buf, k.chainCode...)
+	if k.priv != nil {
+		buf = append(buf, 0)
+		buf = append(buf, k.priv.FillBytes(make([]byte, 32))...)
+	} else {
+		buf = append(buf, k.pub...)
+	}
+	first := sha256.Sum256(buf)
+	second := sha256.Sum256(first[:])
+	return base58Encode(append(buf, second[:4]...))
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func base58Encode(b []byte) string {
+	n := new(big.Int).SetBytes(b)
+	radix, mod := big.NewInt(58), new(big.Int)
+	var out []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		out = append(out, base58Alphabet[0]) // 前导 0 字节编码为 '1'
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
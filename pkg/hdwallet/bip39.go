@@ -0,0 +1,129 @@
+// Package hdwallet 实现 BIP-39 助记词与 BIP-32 / BIP-44 分层确定性密钥派生（secp256k1，以太坊路径 m/44'/60'/0'/0/i）。
+package hdwallet
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+// english.txt 是 BIP-39 官方英文词表（2048 词，sha256 2f5eed53a4727b4bf8880d8f3f199efc90e58503646d9ff8eff3a2ed3b24dbda）
+//
+//go:embed english.txt
+var englishRaw string
+
+var (
+	wordList  = strings.Fields(englishRaw)
+	wordIndex = func() map[string]int {
+		m := make(map[string]int, len(wordList))
+		for i, w := range wordList {
+			m[w] = i
+		}
+		return m
+	}()
+)
+
+var (
+	ErrEntropySize  = errors.New("hdwallet: entropy must be 128-256 bits in multiples of 32")
+	ErrMnemonicSize = errors.New("hdwallet: mnemonic must have 12, 15, 18, 21 or 24 words")
+	ErrChecksum     = errors.New("hdwallet: mnemonic checksum mismatch")
+)
+
+// NewEntropy 生成 bits 位随机熵（128 / 160 / 192 / 224 / 256，对应 12 ~ 24 个词）
+func NewEntropy(bits int) ([]byte, error) {
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return nil, ErrEntropySize
+	}
+	ent := make([]byte, bits/8)
+	if _, err := rand.Read(ent); err != nil {
+		return nil, err
+	}
+	return ent, nil
+}
+
+// NewMnemonic 生成 words 个词的随机助记词
+func NewMnemonic(words int) (string, error) {
+	if words%3 != 0 {
+		return "", ErrMnemonicSize
+	}
+	ent, err := NewEntropy(words / 3 * 32)
+	if err != nil {
+		return "", ErrMnemonicSize
+	}
+	return EntropyToMnemonic(ent)
+}
+
+// EntropyToMnemonic：熵 + sha256(熵) 的前 ENT/32 位校验和，每 11 位映射一个词
+func EntropyToMnemonic(ent []byte) (string, error) {
+	bits := len(ent) * 8
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return "", ErrEntropySize
+	}
+	csBits := bits / 32
+	sum := sha256.Sum256(ent)
+
+	// 拼成一个大整数：entropy << csBits | checksum
+	n := new(big.Int).SetBytes(ent)
+	n.Lsh(n, uint(csBits))
+	n.Or(n, big.NewInt(int64(sum[0]>>(8-csBits))))
+
+	words := make([]string, (bits+csBits)/11)
+	mask := big.NewInt(2047)
+	for i := len(words) - 1; i >= 0; i-- {
+		idx := new(big.Int).And(n, mask).Int64()
+		words[i] = wordList[idx]
+		n.Rsh(n, 11)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy 把助记词还原为熵，并校验词表与校验和
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(norm.NFKD.String(mnemonic))
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, ErrMnemonicSize
+	}
+	n := new(big.Int)
+	for _, w := range words {
+		idx, ok := wordIndex[w]
+		if !ok {
+			return nil, fmt.Errorf("hdwallet: unknown mnemonic word %q", w)
+		}
+		n.Lsh(n, 11)
+		n.Or(n, big.NewInt(int64(idx)))
+	}
+	total := len(words) * 11
+	csBits := total / 33
+	entBits := total - csBits
+
+	cs := new(big.Int).And(n, big.NewInt(1<<csBits-1)).Int64()
+	ent := n.Rsh(n, uint(csBits)).FillBytes(make([]byte, entBits/8))
+	sum := sha256.Sum256(ent)
+	if int64(sum[0]>>(8-csBits)) != cs {
+		return nil, ErrChecksum
+	}
+	return ent, nil
+}
+
+// ValidateMnemonic 检查助记词是否合法（词都在词表中、词数正确、校验和匹配）
+func ValidateMnemonic(mnemonic string) error {
+	_, err := MnemonicToEntropy(mnemonic)
+	return err
+}
+
+// Seed 由助记词与可选口令（“第 25 个词”）派生 64 字节种子：
+// PBKDF2-HMAC-SHA512(NFKD(mnemonic), "mnemonic"+NFKD(passphrase), 2048)。
+// 不校验助记词，需要时先调用 ValidateMnemonic。
+func Seed(mnemonic, passphrase string) []byte {
+	m := strings.Join(strings.Fields(norm.NFKD.String(mnemonic)), " ")
+	salt := "mnemonic" + norm.NFKD.String(passphrase)
+	return pbkdf2.Key([]byte(m), []byte(salt), 2048, 64, sha512.New)
+}
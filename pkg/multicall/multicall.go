@@ -0,0 +1,219 @@
+// Package multicall 把多次只读 eth_call 打包成一次 Multicall3.aggregate3 调用。
+// 每个子调用都设置 allowFailure，单个失败不影响其它结果；链上没有部署 Multicall3
+// （或整批调用失败）时，退回到并发的逐个 eth_call，调用方拿到的结果格式相同。
+package multicall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Address3 是 Multicall3 在绝大多数 EVM 链上的确定性部署地址（见 multicall3.com）
+var Address3 = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+const aggregate3ABI = `[{"type":"function","name":"aggregate3","stateMutability":"payable",
+"inputs":[{"name":"calls","type":"tuple[]","components":[
+  {"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}]}],
+"outputs":[{"name":"returnData","type":"tuple[]","components":[
+  {"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}]}]}]`
+
+var parsedABI = func() abi.ABI {
+	a, err := abi.JSON(strings.NewReader(aggregate3ABI))
+	if err != nil {
+		panic(err)
+	}
+	return a
+}()
+
+// ErrCallFailed：子调用 revert / 执行出错，或返回数据为空（目标没有代码 / 没有该函数）；
+// revert 时 Result.ReturnData 里可能带有 revert 数据
+var ErrCallFailed = errors.New("multicall: call failed")
+
+// Call 是一个只读调用
+type Call struct {
+	Target common.Address
+	Data   []byte
+}
+
+// Result 与 Call 一一对应；Err 为 nil 表示成功
+type Result struct {
+	ReturnData []byte
+	Err        error
+}
+
+// Caller 是所需的最小节点接口（*ethclient.Client 满足）
+type Caller interface {
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+}
+
+// Client 负责分批与回退策略
+type Client struct {
+	caller    Caller
+	Address   common.Address // Multicall3 地址，默认 Address3；设为零地址则总是逐个调用
+	BatchSize int            // 每次 aggregate3 的子调用数，默认 300，避免超出节点 eth_call 的 gas 上限
+	Parallel  int            // 回退到逐个调用时的并发数，默认 8
+
+	mu        sync.Mutex
+	checked   bool // 只缓存成功的探测结果，探测出错时下次重新探测
+	available bool
+}
+
+// New 返回使用默认地址与参数的 Client
+func New(c Caller) *Client {
+	return &Client{caller: c, Address: Address3, BatchSize: 300, Parallel: 8}
+}
+
+// Available 报告链上是否部署了 Multicall3（成功的探测结果会缓存）
+func (c *Client) Available(ctx context.Context) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.checked {
+		return c.available, nil
+	}
+	if c.Address != (common.Address{}) {
+		code, err := c.caller.CodeAt(ctx, c.Address, nil)
+		if err != nil {
+			return false, err
+		}
+		c.available = len(code) > 0
+	}
+	c.checked = true
+	return c.available, nil
+}
+
+// Do 执行全部调用，结果与 calls 顺序一致。只有节点连接类错误才会作为整体错误返回，
+// 单个调用的失败放在对应 Result.Err 中。
+func (c *Client) Do(ctx context.Context, calls []Call, block *big.Int) ([]Result, error) {
+	results := make([]Result, len(calls))
+	ok, err := c.Available(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := c.parallel(ctx, calls, results, block); err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+	size := c.BatchSize
+	if size <= 0 {
+		size = 300
+	}
+	for lo := 0; lo < len(calls); lo += size {
+		hi := min(lo+size, len(calls))
+		if err := c.aggregate(ctx, calls[lo:hi], results[lo:hi], block); err != nil {
+			// 整批失败（如超出 gas 上限、该区块时 Multicall3 尚未部署）时这一批改为逐个调用
+			if err := c.parallel(ctx, calls[lo:hi], results[lo:hi], block); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return results, nil
+}
+
+func (c *Client) aggregate(ctx context.Context, calls []Call, results []Result, block *big.Int) error {
+	type call3 struct {
+		Target       common.Address
+		AllowFailure bool
+		CallData     []byte
+	}
+	in := make([]call3, len(calls))
+	for i, cl := range calls {
+		in[i] = call3{Target: cl.Target, AllowFailure: true, CallData: cl.Data}
+	}
+	data, err := parsedABI.Pack("aggregate3", in)
+	if err != nil {
+		return err
+	}
+	raw, err := c.caller.CallContract(ctx, ethereum.CallMsg{To: &c.Address, Data: data}, block)
+	if err != nil {
+		return err
+	}
+	var out []struct {
+		Success    bool
+		ReturnData []byte
+	}
+	if err := parsedABI.UnpackIntoInterface(&out, "aggregate3", raw); err != nil {
+		return err
+	}
+	if len(out) != len(calls) {
+		return fmt.Errorf("multicall: got %d results for %d calls", len(out), len(calls))
+	}
+	for i, r := range out {
+		results[i] = Result{ReturnData: r.ReturnData}
+		if !r.Success || len(r.ReturnData) == 0 {
+			results[i].Err = ErrCallFailed
+		}
+	}
+	return nil
+}
+
+// parallel 用 Parallel 个 goroutine 逐个 eth_call。节点返回的执行错误（revert 等）记入对应 Result，
+// 连接类错误（超时、断线、HTTP 错误）返回第一个
+func (c *Client) parallel(ctx context.Context, calls []Call, results []Result, block *big.Int) error {
+	workers := c.Parallel
+	if workers <= 0 {
+		workers = 8
+	}
+	jobs := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		transErr error
+	)
+	for range min(workers, len(calls)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				to := calls[i].Target
+				raw, err := c.caller.CallContract(ctx, ethereum.CallMsg{To: &to, Data: calls[i].Data}, block)
+				switch {
+				case err == nil && len(raw) == 0:
+					results[i] = Result{Err: ErrCallFailed}
+				case err == nil:
+					results[i] = Result{ReturnData: raw}
+				case isExecError(err):
+					results[i] = Result{ReturnData: revertData(err), Err: fmt.Errorf("%w: %v", ErrCallFailed, err)}
+				default:
+					errOnce.Do(func() { transErr = err })
+				}
+			}
+		}()
+	}
+	for i := range calls {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return transErr
+}
+
+// isExecError 判断错误是不是节点执行调用后返回的 JSON-RPC 错误（revert、out of gas 等）；
+// 连接失败、超时、HTTP 状态码错误都不带 JSON-RPC 错误码
+func isExecError(err error) bool {
+	var re rpc.Error
+	return errors.As(err, &re)
+}
+
+// revertData 取出 revert 错误附带的返回数据，没有时返回 nil
+func revertData(err error) []byte {
+	var de rpc.DataError
+	if !errors.As(err, &de) {
+		return nil
+	}
+	s, _ := de.ErrorData().(string)
+	data, _ := hexutil.Decode(s)
+	return data
+}
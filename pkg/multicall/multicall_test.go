@@ -0,0 +1,210 @@
+package multicall
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// revertErr 模拟节点对 revert 返回的 JSON-RPC 错误（code 3，带 revert 数据）
+type revertErr struct{ data string }
+
+func (e revertErr) Error() string          { return "execution reverted" }
+func (e revertErr) ErrorCode() int         { return 3 }
+func (e revertErr) ErrorData() interface{} { return e.data }
+
+var (
+	okTarget     = common.HexToAddress("0x1001") // 返回 calldata 本身
+	revertTarget = common.HexToAddress("0x1002") // 总是 revert
+	emptyTarget  = common.HexToAddress("0x1003") // 没有代码：返回空
+	errNet       = errors.New("dial tcp: connection refused")
+)
+
+// fakeCaller 在内存里模拟节点：按目标地址决定子调用结果，并实现 Multicall3.aggregate3
+type fakeCaller struct {
+	mu         sync.Mutex
+	hasCode    bool
+	codeErrs   int // CodeAt 前几次返回错误
+	codeCalls  int
+	maxBatch   int // aggregate3 子调用超过这个数时整批失败（模拟超出 gas 上限）；0 表示不限
+	aggregates int
+	singles    int
+	netDown    bool // 逐个调用时返回连接错误
+}
+
+func (f *fakeCaller) CodeAt(ctx context.Context, a common.Address, n *big.Int) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.codeCalls++
+	if f.codeErrs > 0 {
+		f.codeErrs--
+		return nil, errNet
+	}
+	if f.hasCode && a == Address3 {
+		return []byte{0x60}, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeCaller) CallContract(ctx context.Context, msg ethereum.CallMsg, n *big.Int) ([]byte, error) {
+	if *msg.To == Address3 {
+		return f.aggregate3(msg.Data)
+	}
+	f.mu.Lock()
+	f.singles++
+	down := f.netDown
+	f.mu.Unlock()
+	if down {
+		return nil, errNet
+	}
+	return single(*msg.To, msg.Data)
+}
+
+func single(to common.Address, data []byte) ([]byte, error) {
+	switch to {
+	case okTarget:
+		return data, nil
+	case revertTarget:
+		return nil, revertErr{data: "0xdeadbeef"}
+	default:
+		return nil, nil
+	}
+}
+
+func (f *fakeCaller) aggregate3(data []byte) ([]byte, error) {
+	m := parsedABI.Methods["aggregate3"]
+	args, err := m.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	type call3 struct {
+		Target       common.Address
+		AllowFailure bool
+		CallData     []byte
+	}
+	calls := *abi.ConvertType(args[0], new([]call3)).(*[]call3)
+	f.mu.Lock()
+	f.aggregates++
+	tooBig := f.maxBatch > 0 && len(calls) > f.maxBatch
+	f.mu.Unlock()
+	if tooBig {
+		return nil, revertErr{data: "0x"}
+	}
+	type result struct {
+		Success    bool
+		ReturnData []byte
+	}
+	out := make([]result, len(calls))
+	for i, c := range calls {
+		ret, err := single(c.Target, c.CallData)
+		out[i] = result{Success: err == nil, ReturnData: ret}
+	}
+	return m.Outputs.Pack(out)
+}
+
+// mixedCalls 依次循环 ok / revert / 空返回三种目标
+func mixedCalls(n int) []Call {
+	targets := []common.Address{okTarget, revertTarget, emptyTarget}
+	calls := make([]Call, n)
+	for i := range calls {
+		calls[i] = Call{Target: targets[i%3], Data: []byte{byte(i), 0xab}}
+	}
+	return calls
+}
+
+func checkMixed(t *testing.T, calls []Call, results []Result) {
+	t.Helper()
+	if len(results) != len(calls) {
+		t.Fatalf("got %d results for %d calls", len(results), len(calls))
+	}
+	for i, r := range results {
+		switch calls[i].Target {
+		case okTarget:
+			if r.Err != nil || !bytes.Equal(r.ReturnData, calls[i].Data) {
+				t.Errorf("call %d: %x, %v, want %x", i, r.ReturnData, r.Err, calls[i].Data)
+			}
+		default:
+			if !errors.Is(r.Err, ErrCallFailed) {
+				t.Errorf("call %d to %s: err = %v, want ErrCallFailed", i, calls[i].Target.Hex(), r.Err)
+			}
+		}
+	}
+}
+
+func TestAggregateMixed(t *testing.T) {
+	f := &fakeCaller{hasCode: true}
+	c := New(f)
+	c.BatchSize = 4
+	calls := mixedCalls(10)
+	results, err := c.Do(context.Background(), calls, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkMixed(t, calls, results)
+	if f.aggregates != 3 || f.singles != 0 {
+		t.Errorf("aggregates = %d singles = %d, want 3 / 0", f.aggregates, f.singles)
+	}
+}
+
+func TestBatchFallback(t *testing.T) {
+	// 前两批各 4 个超出上限，整批失败后逐个调用；最后一批 2 个仍走 aggregate3
+	f := &fakeCaller{hasCode: true, maxBatch: 3}
+	c := New(f)
+	c.BatchSize = 4
+	calls := mixedCalls(10)
+	results, err := c.Do(context.Background(), calls, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkMixed(t, calls, results)
+	if f.aggregates != 3 || f.singles != 8 {
+		t.Errorf("aggregates = %d singles = %d, want 3 / 8", f.aggregates, f.singles)
+	}
+	if !bytes.Equal(results[1].ReturnData, []byte{0xde, 0xad, 0xbe, 0xef}) {
+		t.Errorf("revert data = %x, want deadbeef", results[1].ReturnData)
+	}
+
+	// 回退时节点连不上：作为整体错误返回，而不是塞进各个 Result
+	f.netDown = true
+	if _, err := c.Do(context.Background(), calls, nil); !errors.Is(err, errNet) {
+		t.Errorf("err = %v, want %v", err, errNet)
+	}
+}
+
+func TestNoCode(t *testing.T) {
+	// 第一次探测出错：错误返回且不缓存；之后探测到没有代码，缓存结果并逐个调用
+	f := &fakeCaller{codeErrs: 1}
+	c := New(f)
+	calls := mixedCalls(7)
+	if _, err := c.Do(context.Background(), calls, nil); !errors.Is(err, errNet) {
+		t.Fatalf("err = %v, want %v", err, errNet)
+	}
+	for range 2 {
+		results, err := c.Do(context.Background(), calls, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkMixed(t, calls, results)
+	}
+	if f.codeCalls != 2 {
+		t.Errorf("CodeAt calls = %d, want 2", f.codeCalls)
+	}
+	if f.aggregates != 0 || f.singles != 14 {
+		t.Errorf("aggregates = %d singles = %d, want 0 / 14", f.aggregates, f.singles)
+	}
+
+	// 零地址：不探测，总是逐个调用
+	f = &fakeCaller{hasCode: true}
+	c = New(f)
+	c.Address = common.Address{}
+	if ok, err := c.Available(context.Background()); ok || err != nil || f.codeCalls != 0 {
+		t.Errorf("zero address: Available = %v, %v, CodeAt calls = %d", ok, err, f.codeCalls)
+	}
+}
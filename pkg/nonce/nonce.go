@@ -0,0 +1,225 @@
+// Package nonce 在本地为账户分配 nonce，解决并发发送多笔交易时各自 PendingNonceAt 拿到同一个 nonce 的问题。
+//
+// 分配规则：
+//   - 某账户第一次分配时用 PendingNonceAt 同步链上（含交易池）的下一个 nonce
+//   - 之后在本地递增，不再每笔都查节点
+//   - 节点明确拒收的 nonce 回收，下一次优先复用，避免留下空洞卡住后面的交易
+//   - 结果不确定的广播（超时、连接断开）不回收：交易可能已进池，重新同步后由节点的 pending nonce 判断
+//   - 节点报 "nonce too low"（被别的进程 / 钱包抢先用掉）时标记为需要重新同步
+package nonce
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Source 是同步 nonce 需要的节点能力；*ethclient.Client 满足
+type Source interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// Manager 按账户分配 nonce，可被多个 goroutine 并发使用
+type Manager struct {
+	src Source
+
+	mu       sync.Mutex
+	accounts map[common.Address]*account
+}
+
+// account 是单个账户的分配状态；同步 nonce 时只锁这个账户，不影响其它账户
+type account struct {
+	mu       sync.Mutex
+	synced   bool
+	next     uint64   // 下一个从未分配过的 nonce
+	released []uint64 // 回收的 nonce，升序，优先复用
+	unsure   []uint64 // 广播结果不确定的 nonce，下次同步时按节点的 pending nonce 决定回收还是作废
+}
+
+// NewManager 创建 Manager；各账户在第一次 Next 时才向节点同步
+func NewManager(src Source) *Manager {
+	return &Manager{src: src, accounts: make(map[common.Address]*account)}
+}
+
+func (m *Manager) account(addr common.Address) *account {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a, ok := m.accounts[addr]
+	if !ok {
+		a = &account{}
+		m.accounts[addr] = a
+	}
+	return a
+}
+
+// Next 为 addr 分配一个 nonce。调用方广播后必须用 Settle 处理结果；确定没有广播出去（如签名失败）时直接 Release。
+func (m *Manager) Next(ctx context.Context, addr common.Address) (uint64, error) {
+	a := m.account(addr)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.synced {
+		pending, err := m.src.PendingNonceAt(ctx, addr)
+		if err != nil {
+			return 0, fmt.Errorf("sync nonce of %s: %w", addr.Hex(), err)
+		}
+		// 本地已分配出去、还在广播途中的 nonce 不能回退，只会往前追
+		a.next = max(a.next, pending)
+		a.released = slices.DeleteFunc(a.released, func(n uint64) bool { return n < pending })
+		// 节点的 pending nonce 没越过它，说明那笔交易没进池，可以回收
+		for _, n := range a.unsure {
+			if i, found := slices.BinarySearch(a.released, n); n >= pending && n < a.next && !found {
+				a.released = slices.Insert(a.released, i, n)
+			}
+		}
+		a.unsure = nil
+		a.synced = true
+	}
+	if len(a.released) > 0 {
+		n := a.released[0]
+		a.released = a.released[1:]
+		return n, nil
+	}
+	n := a.next
+	a.next++
+	return n, nil
+}
+
+// Release 归还一个确定没有进池的 nonce
+func (m *Manager) Release(addr common.Address, n uint64) {
+	a := m.account(addr)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if n >= a.next || slices.Contains(a.released, n) || slices.Contains(a.unsure, n) {
+		return // 不是本 Manager 分配的、已归还过，或正等待同步确认
+	}
+	if n == a.next-1 {
+		// 归还的是最新一个：直接回退，并顺带收回紧挨着的已归还 nonce
+		a.next--
+		for len(a.released) > 0 && a.released[len(a.released)-1] == a.next-1 {
+			a.released = a.released[:len(a.released)-1]
+			a.next--
+		}
+		return
+	}
+	i, _ := slices.BinarySearch(a.released, n)
+	a.released = slices.Insert(a.released, i, n)
+}
+
+// Resync 标记 addr 下次分配前重新向节点同步
+func (m *Manager) Resync(addr common.Address) {
+	a := m.account(addr)
+	a.mu.Lock()
+	a.synced = false
+	a.mu.Unlock()
+}
+
+// Reset 丢弃 addr 的全部本地状态。交易被节点丢弃（不是广播失败）导致 nonce 出现空洞时使用。
+func (m *Manager) Reset(addr common.Address) {
+	m.mu.Lock()
+	delete(m.accounts, addr)
+	m.mu.Unlock()
+}
+
+// Settle 根据广播结果处理 nonce：成功什么都不做；"nonce too low" 标记重新同步（该 nonce 已被占用，不再回收）；
+// 交易池明确拒收（见 IsRejected）时归还 nonce；其它错误（超时、连接断开等）无法确定交易是否已进池，
+// 保留该 nonce 并标记重新同步，由下次同步时的 PendingNonceAt 决定是否回收。
+func (m *Manager) Settle(addr common.Address, n uint64, sendErr error) {
+	switch {
+	case sendErr == nil, IsAlreadyKnown(sendErr):
+	case IsNonceTooLow(sendErr):
+		m.Resync(addr)
+	case IsRejected(sendErr):
+		m.Release(addr, n)
+	default:
+		a := m.account(addr)
+		a.mu.Lock()
+		if n < a.next && !slices.Contains(a.unsure, n) {
+			a.unsure = append(a.unsure, n)
+		}
+		a.synced = false
+		a.mu.Unlock()
+	}
+}
+
+// maxAttempts：遇到 "nonce too low" 时最多重新分配几次
+const maxAttempts = 3
+
+// Send 分配 nonce → build 构造并签名 → send 广播，并按结果 Settle。
+// "nonce too low" 时重新同步后换新 nonce 重试；返回最终广播成功的交易。
+func (m *Manager) Send(ctx context.Context, addr common.Address,
+	build func(nonce uint64) (*types.Transaction, error),
+	send func(ctx context.Context, tx *types.Transaction) error,
+) (*types.Transaction, error) {
+	var lastErr error
+	for range maxAttempts {
+		n, err := m.Next(ctx, addr)
+		if err != nil {
+			return nil, err
+		}
+		tx, err := build(n)
+		if err != nil {
+			m.Release(addr, n)
+			return nil, err
+		}
+		err = send(ctx, tx)
+		m.Settle(addr, n, err)
+		if err == nil || IsAlreadyKnown(err) {
+			return tx, nil
+		}
+		if !IsNonceTooLow(err) {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("nonce still too low after %d attempts: %w", maxAttempts, lastErr)
+}
+
+// IsNonceTooLow 识别 nonce 已被使用的报错
+func IsNonceTooLow(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "nonce too low")
+}
+
+// rejections 是交易池明确拒收交易时的报错片段（geth txpool / RPC 校验），这些情况下交易一定没有进池
+var rejections = []string{
+	"underpriced", // transaction underpriced / replacement transaction underpriced
+	"insufficient funds",
+	"intrinsic gas too low",
+	"exceeds block gas limit",
+	"fee cap less than block base fee",
+	"max fee per gas less than block base fee",
+	"tip higher than fee cap",
+	"max priority fee per gas higher than max fee per gas",
+	"exceeds the configured cap", // RPC 的 txfeecap
+	"oversized data",
+	"invalid sender",
+	"invalid chain id",
+	"only replay-protected",
+	"nonce too high",
+	"txpool is full",
+}
+
+// IsRejected 识别交易池明确拒收的报错；超时、连接断开等结果不确定的错误返回 false
+func IsRejected(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, r := range rejections {
+		if strings.Contains(msg, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAlreadyKnown 识别同一笔交易重复广播的报错（交易已在池中，视为成功）
+func IsAlreadyKnown(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "already known")
+}
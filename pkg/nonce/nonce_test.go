@@ -0,0 +1,182 @@
+package nonce
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeSource 模拟节点的 pending nonce，并记录被查询的次数
+type fakeSource struct {
+	mu      sync.Mutex
+	pending uint64
+	syncs   int
+}
+
+func (f *fakeSource) PendingNonceAt(ctx context.Context, a common.Address) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.syncs++
+	return f.pending, nil
+}
+
+func (f *fakeSource) set(n uint64) {
+	f.mu.Lock()
+	f.pending = n
+	f.mu.Unlock()
+}
+
+var addr = common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+
+func mustNext(t *testing.T, m *Manager) uint64 {
+	t.Helper()
+	n, err := m.Next(context.Background(), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestConcurrentNext(t *testing.T) {
+	src := &fakeSource{pending: 7}
+	m := NewManager(src)
+	const total = 200
+	got := make([]uint64, total)
+	var wg sync.WaitGroup
+	for i := range total {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := m.Next(context.Background(), addr)
+			if err != nil {
+				t.Error(err)
+			}
+			got[i] = n
+		}()
+	}
+	wg.Wait()
+	slices.Sort(got)
+	for i, n := range got {
+		if n != uint64(7+i) {
+			t.Fatalf("nonce #%d = %d, want %d (duplicate or gap)", i, n, 7+i)
+		}
+	}
+	if src.syncs != 1 {
+		t.Errorf("PendingNonceAt calls = %d, want 1", src.syncs)
+	}
+}
+
+func TestRelease(t *testing.T) {
+	m := NewManager(&fakeSource{pending: 10})
+	for range 4 {
+		mustNext(t, m) // 10..13
+	}
+
+	// 归还最新一个：下一次直接复用
+	m.Release(addr, 13)
+	if n := mustNext(t, m); n != 13 {
+		t.Errorf("after releasing 13, Next = %d", n)
+	}
+
+	// 归还中间的：先补空洞，之后接着往后分配，不会重复
+	m.Release(addr, 11)
+	m.Release(addr, 11) // 重复归还无效
+	var seq []uint64
+	for range 3 {
+		seq = append(seq, mustNext(t, m))
+	}
+	if !slices.Equal(seq, []uint64{11, 14, 15}) {
+		t.Errorf("after releasing 11, Next = %v, want [11 14 15]", seq)
+	}
+
+	// 从未分配过的 nonce 不接受
+	m.Release(addr, 99)
+	if n := mustNext(t, m); n != 16 {
+		t.Errorf("after releasing unallocated 99, Next = %d, want 16", n)
+	}
+}
+
+func TestSettle(t *testing.T) {
+	ctx := context.Background()
+	src := &fakeSource{pending: 5}
+	m := NewManager(src)
+
+	// nonce too low：不回收，重新同步后从节点的 pending nonce 继续
+	n := mustNext(t, m)
+	m.Settle(addr, n, errors.New("nonce too low: next nonce 8, tx nonce 5"))
+	src.set(8)
+	if n := mustNext(t, m); n != 8 || src.syncs != 2 {
+		t.Errorf("after nonce too low, Next = %d syncs = %d, want 8 / 2", n, src.syncs)
+	}
+
+	// already known 视为成功；明确拒收时回收
+	m.Settle(addr, 8, errors.New("already known"))
+	n = mustNext(t, m)
+	m.Settle(addr, n, errors.New("replacement transaction underpriced"))
+	if got := mustNext(t, m); n != 9 || got != 9 {
+		t.Errorf("already known / rejected: nonces %d, %d, want 9, 9", n, got)
+	}
+
+	// 结果不确定：保留 nonce 并重新同步；节点没见到这笔交易时回收，见到了则作废
+	m.Settle(addr, 9, errors.New("context deadline exceeded"))
+	if n := mustNext(t, m); n != 9 || src.syncs != 3 {
+		t.Errorf("unseen ambiguous tx: Next = %d syncs = %d, want 9 / 3", n, src.syncs)
+	}
+	m.Settle(addr, 9, errors.New("read: connection reset by peer"))
+	src.set(10)
+	if n := mustNext(t, m); n != 10 {
+		t.Errorf("ambiguous tx that reached the pool: Next = %d, want 10", n)
+	}
+
+	// 不确定的 nonce 同步前不会被其它调用方拿到
+	m.Settle(addr, 10, errors.New("i/o timeout"))
+	m.Release(addr, 10) // 同步之前不应出现两个 10
+	a, b := mustNext(t, m), mustNext(t, m)
+	if a == b {
+		t.Errorf("duplicate nonce %d after ambiguous error", a)
+	}
+
+	// Send：nonce too low 时自动重试
+	src = &fakeSource{pending: 3}
+	m = NewManager(src)
+	var sent []uint64
+	tx, err := m.Send(ctx, addr, func(n uint64) (*types.Transaction, error) {
+		return types.NewTx(&types.LegacyTx{Nonce: n}), nil
+	}, func(ctx context.Context, tx *types.Transaction) error {
+		sent = append(sent, tx.Nonce())
+		if tx.Nonce() < 4 {
+			src.set(4)
+			return errors.New("nonce too low")
+		}
+		return nil
+	})
+	if err != nil || tx.Nonce() != 4 || !slices.Equal(sent, []uint64{3, 4}) {
+		t.Errorf("Send = %v, %v, sent %v", tx, err, sent)
+	}
+}
+
+func TestIsRejected(t *testing.T) {
+	for msg, want := range map[string]bool{
+		"transaction underpriced":                        true,
+		"replacement transaction underpriced":            true,
+		"insufficient funds for gas * price + value":     true,
+		"intrinsic gas too low":                          true,
+		"max fee per gas less than block base fee":       true,
+		"tx fee (1.20 ether) exceeds the configured cap": true,
+		"context deadline exceeded":                      false,
+		"read tcp: connection reset by peer":             false,
+		"502 Bad Gateway":                                false,
+	} {
+		if got := IsRejected(errors.New(msg)); got != want {
+			t.Errorf("IsRejected(%q) = %v, want %v", msg, got, want)
+		}
+	}
+	if IsRejected(nil) {
+		t.Error("IsRejected(nil) = true")
+	}
+}
@@ -0,0 +1,148 @@
+// Package rawtx 支持离线（air-gapped）签名流程：联网机器生成未签名交易描述（JSON），
+// 离线机器签名得到 raw RLP hex，再回到联网机器解码确认后广播。
+package rawtx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Unsigned 是待签名的 EIP-1559 交易描述。数值字段用 JSON 十进制数字，方便在离线机器上人工核对；
+// To 为空表示部署合约。From 只用于签名前核对账户，不参与签名。
+type Unsigned struct {
+	ChainID   *big.Int        `json:"chainId"`
+	From      *common.Address `json:"from,omitempty"`
+	Nonce     uint64          `json:"nonce"`
+	To        *common.Address `json:"to"`
+	Value     *big.Int        `json:"value"`
+	Gas       uint64          `json:"gas"`
+	GasTipCap *big.Int        `json:"maxPriorityFeePerGas"`
+	GasFeeCap *big.Int        `json:"maxFeePerGas"`
+	Data      hexutil.Bytes   `json:"data,omitempty"`
+}
+
+// Validate 检查必填字段与费用关系
+func (u *Unsigned) Validate() error {
+	switch {
+	case u.ChainID == nil || u.ChainID.Sign() <= 0:
+		return errors.New("rawtx: chainId missing")
+	case u.Gas == 0:
+		return errors.New("rawtx: gas missing")
+	case u.GasTipCap == nil || u.GasFeeCap == nil:
+		return errors.New("rawtx: maxPriorityFeePerGas / maxFeePerGas missing")
+	case u.GasFeeCap.Cmp(u.GasTipCap) < 0:
+		return fmt.Errorf("rawtx: maxFeePerGas %s < maxPriorityFeePerGas %s", u.GasFeeCap, u.GasTipCap)
+	case u.Value != nil && u.Value.Sign() < 0:
+		return errors.New("rawtx: negative value")
+	case u.To == nil && len(u.Data) == 0:
+		return errors.New("rawtx: contract creation without data")
+	}
+	return nil
+}
+
+// Tx 转成未签名的 DynamicFeeTx
+func (u *Unsigned) Tx() (*types.Transaction, error) {
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+	value := u.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   u.ChainID,
+		Nonce:     u.Nonce,
+		To:        u.To,
+		Value:     value,
+		Gas:       u.Gas,
+		GasTipCap: u.GasTipCap,
+		GasFeeCap: u.GasFeeCap,
+		Data:      u.Data,
+	}), nil
+}
+
+// Load 读取未签名交易 JSON 文件；未知字段视为错误，避免拼错字段名被静默忽略
+func Load(path string) (*Unsigned, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	var u Unsigned
+	if err := dec.Decode(&u); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := u.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &u, nil
+}
+
+// Save 把未签名交易写成缩进 JSON；path 为空或 "-" 时写到 stdout
+func (u *Unsigned) Save(path string) error {
+	raw, err := json.MarshalIndent(u, "", "  ")
+	if err != nil {
+		return err
+	}
+	raw = append(raw, '\n')
+	if path == "" || path == "-" {
+		_, err = os.Stdout.Write(raw)
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
+
+// Encode 返回签名交易的 raw hex（0x 前缀，typed tx 为 type || rlp，legacy 为 rlp），即 eth_sendRawTransaction 的参数
+func Encode(tx *types.Transaction) (string, error) {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return hexutil.Encode(raw), nil
+}
+
+// Decode 解析 raw hex；0x 前缀可省略，忽略首尾空白（方便直接读文件或粘贴）
+func Decode(s string) (*types.Transaction, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		s = "0x" + s
+	}
+	raw, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("rawtx: decode hex: %w", err)
+	}
+	if len(raw) == 0 {
+		return nil, errors.New("rawtx: empty raw tx")
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("rawtx: decode tx: %w", err)
+	}
+	return tx, nil
+}
+
+// ReadArg 把命令行参数当作 raw hex；若它是一个存在的文件，则读取文件内容
+func ReadArg(arg string) (*types.Transaction, error) {
+	if b, err := os.ReadFile(arg); err == nil {
+		return Decode(string(b))
+	}
+	return Decode(arg)
+}
+
+// Sender 用 LatestSignerForChainID 从签名恢复发送方；legacy 非 EIP-155 交易用 HomesteadSigner
+func Sender(tx *types.Transaction) (common.Address, error) {
+	if !tx.Protected() {
+		return types.Sender(types.HomesteadSigner{}, tx)
+	}
+	return types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+}
@@ -0,0 +1,46 @@
+// Package sigutil 处理 65 字节 [R || S || V] 签名里 V 的两种约定：
+// go-ethereum 的 crypto.Sign / SigToPub 用 0/1，钱包（personal_sign、eth_signTypedData）和合约 ecrecover 用 27/28。
+package sigutil
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ToWallet 把 crypto.Sign 产出的签名（V 为 0/1）原地改成钱包约定的 27/28，并返回 sig
+func ToWallet(sig []byte) []byte {
+	if len(sig) == crypto.SignatureLength && sig[crypto.RecoveryIDOffset] < 27 {
+		sig[crypto.RecoveryIDOffset] += 27
+	}
+	return sig
+}
+
+// Normalize 返回 V 为 0/1 的副本，可直接交给 crypto.SigToPub；V 接受 0/1 或 27/28，不修改 sig
+func Normalize(sig []byte) ([]byte, error) {
+	if len(sig) != crypto.SignatureLength {
+		return nil, fmt.Errorf("signature must be %d bytes, got %d", crypto.SignatureLength, len(sig))
+	}
+	out := common.CopyBytes(sig)
+	if out[crypto.RecoveryIDOffset] >= 27 {
+		out[crypto.RecoveryIDOffset] -= 27
+	}
+	if out[crypto.RecoveryIDOffset] > 1 {
+		return nil, fmt.Errorf("invalid recovery id %d", sig[crypto.RecoveryIDOffset])
+	}
+	return out, nil
+}
+
+// Recover 从 32 字节哈希与签名恢复签名者地址；V 接受 0/1 或 27/28
+func Recover(hash, sig []byte) (common.Address, error) {
+	norm, err := Normalize(sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	pub, err := crypto.SigToPub(hash, norm)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
@@ -0,0 +1,50 @@
+package sigutil
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestRoundTrip(t *testing.T) {
+	key, err := crypto.HexToECDSA("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	hash := crypto.Keccak256([]byte("hello"))
+	raw, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := raw[crypto.RecoveryIDOffset]
+
+	wallet := ToWallet(append([]byte(nil), raw...))
+	if wallet[crypto.RecoveryIDOffset] != v+27 {
+		t.Fatalf("ToWallet V = %d, want %d", wallet[crypto.RecoveryIDOffset], v+27)
+	}
+	if again := ToWallet(wallet); again[crypto.RecoveryIDOffset] != v+27 {
+		t.Errorf("ToWallet is not idempotent: V = %d", again[crypto.RecoveryIDOffset])
+	}
+
+	// 0/1 与 27/28 两种 V 都能恢复出同一地址，且不修改入参
+	for name, sig := range map[string][]byte{"0/1": raw, "27/28": wallet} {
+		before := sig[crypto.RecoveryIDOffset]
+		got, err := Recover(hash, sig)
+		if err != nil || got != addr {
+			t.Errorf("%s: Recover = %s, %v, want %s", name, got.Hex(), err, addr.Hex())
+		}
+		if sig[crypto.RecoveryIDOffset] != before {
+			t.Errorf("%s: Recover modified the signature", name)
+		}
+	}
+
+	bad := append([]byte(nil), raw...)
+	bad[crypto.RecoveryIDOffset] = 29
+	if _, err := Recover(hash, bad); err == nil {
+		t.Error("V = 29 accepted")
+	}
+	if _, err := Recover(hash, raw[:64]); err == nil {
+		t.Error("64-byte signature accepted")
+	}
+}
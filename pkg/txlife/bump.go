@@ -0,0 +1,213 @@
+package txlife
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// MinBumpPercent 是 geth 交易池接受同 nonce 替换交易的最低加价比例（tip 与 feeCap 都要满足）
+const MinBumpPercent = 10
+
+// BumpFee 把 old 提高 percent%（向上取整），并保证至少 +1 wei；percent 低于 MinBumpPercent 时按 MinBumpPercent 算
+func BumpFee(old *big.Int, percent int64) *big.Int {
+	if percent < MinBumpPercent {
+		percent = MinBumpPercent
+	}
+	if old == nil || old.Sign() == 0 {
+		return big.NewInt(1)
+	}
+	n := new(big.Int).Mul(old, big.NewInt(100+percent))
+	n.Add(n, big.NewInt(99))
+	n.Div(n, big.NewInt(100))
+	if n.Cmp(old) <= 0 {
+		n.Add(old, common.Big1)
+	}
+	return n
+}
+
+// maxBig 返回较大者（b 为 nil 时返回 a）
+func maxBig(a, b *big.Int) *big.Int {
+	if b != nil && b.Cmp(a) > 0 {
+		return new(big.Int).Set(b)
+	}
+	return a
+}
+
+// Bumped 复制 tx 并把费用提高 percent%：动态费交易同时提高 tip 与 feeCap，legacy / access-list 交易提高 gasPrice。
+// minTip / minFeeCap（可为 nil）是当前网络建议值，加价后仍低于它们时直接取建议值，避免加了价还是卡住。
+// 注意：未签名的 legacy 交易不带链 ID（ChainId() 由 V 推导，签名前没有意义），签名时要用原交易的 tx.ChainId()。
+func Bumped(tx *types.Transaction, percent int64, minTip, minFeeCap *big.Int) (*types.Transaction, error) {
+	switch tx.Type() {
+	case types.DynamicFeeTxType:
+		tip := maxBig(BumpFee(tx.GasTipCap(), percent), minTip)
+		feeCap := maxBig(BumpFee(tx.GasFeeCap(), percent), minFeeCap)
+		if feeCap.Cmp(tip) < 0 {
+			feeCap = new(big.Int).Set(tip)
+		}
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  tip,
+			GasFeeCap:  feeCap,
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		}), nil
+	case types.LegacyTxType:
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    tx.Nonce(),
+			GasPrice: maxBig(BumpFee(tx.GasPrice(), percent), minFeeCap),
+			Gas:      tx.Gas(),
+			To:       tx.To(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		}), nil
+	case types.AccessListTxType:
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasPrice:   maxBig(BumpFee(tx.GasPrice(), percent), minFeeCap),
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		}), nil
+	}
+	return nil, fmt.Errorf("replace tx type %d not supported", tx.Type())
+}
+
+// Cancellation 构造取消交易：同 nonce、0 value、给自己转账、gas 21000，费用在原交易基础上加价；
+// 与 Bumped 一样，签名时要用原交易的 tx.ChainId()
+func Cancellation(tx *types.Transaction, from common.Address, percent int64, minTip, minFeeCap *big.Int) (*types.Transaction, error) {
+	bumped, err := Bumped(tx, percent, minTip, minFeeCap)
+	if err != nil {
+		return nil, err
+	}
+	if bumped.Type() == types.DynamicFeeTxType {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   bumped.ChainId(),
+			Nonce:     bumped.Nonce(),
+			GasTipCap: bumped.GasTipCap(),
+			GasFeeCap: bumped.GasFeeCap(),
+			Gas:       21000,
+			To:        &from,
+			Value:     new(big.Int),
+		}), nil
+	}
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    bumped.Nonce(),
+		GasPrice: bumped.GasPrice(),
+		Gas:      21000,
+		To:       &from,
+		Value:    new(big.Int),
+	}), nil
+}
+
+// IsUnderpriced 识别交易池拒绝替换交易的报错（加价不足 10% 等）
+func IsUnderpriced(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "underpriced") || strings.Contains(msg, "fee too low")
+}
+
+// Bumper 在等待期间按固定间隔自动加价重发，直到任意一个版本上链
+type Bumper struct {
+	Tracker   *Tracker
+	Sign      func(*types.Transaction, *big.Int) (*types.Transaction, error) // 按给定链 ID 对加价后的交易签名
+	Send      func(context.Context, *types.Transaction) error                // 通常是 client.SendTransaction
+	Every     time.Duration                                                  // 多久没上链就加价一次
+	Percent   int64                                                          // 每次加价比例，默认（且至少）MinBumpPercent
+	MaxFeeCap *big.Int                                                       // 可选：feeCap / gasPrice 上限，达到后不再加价
+	MaxBumps  int                                                            // 最多加价次数，0 表示不限
+	OnBump    func(old, replacement *types.Transaction)                      // 可选：每次重发后回调
+}
+
+// Wait 广播后的 tx 交给 Bumper 等待。返回最终上链那一版的回执（可能是原交易，也可能是某次加价后的交易）。
+func (b *Bumper) Wait(ctx context.Context, tx *types.Transaction, onUpdate func(Update)) (*types.Receipt, error) {
+	chainID := tx.ChainId() // 取自已签名的原交易；加价后的 legacy 交易签名前推导不出链 ID
+	sent := []*types.Transaction{tx}
+	base := tx // 下一次加价的基准；替换被拒时也前移，避免原地重复同一个价格
+	bumps := 0
+	for {
+		latest := sent[len(sent)-1]
+		rctx, cancel := context.WithTimeout(ctx, b.Every)
+		rcpt, err := b.Tracker.Wait(rctx, latest, onUpdate)
+		cancel()
+
+		switch {
+		case err == nil:
+			return rcpt, nil
+		case errors.Is(err, ErrReplaced):
+			// 同 nonce 的某个早先版本抢先上链
+			return b.findMined(ctx, sent)
+		case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+			// 本轮没等到，继续往下加价
+		default:
+			return rcpt, err
+		}
+
+		if b.MaxBumps > 0 && bumps >= b.MaxBumps {
+			continue // 次数用完，只等不加
+		}
+		next, err := Bumped(base, b.Percent, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		base = next
+		if b.MaxFeeCap != nil && feeCapOf(next).Cmp(b.MaxFeeCap) > 0 {
+			continue // 超过上限，只等不加
+		}
+		signed, err := b.Sign(next, chainID)
+		if err != nil {
+			return nil, fmt.Errorf("sign replacement: %w", err)
+		}
+		if err := b.Send(ctx, signed); err != nil {
+			if IsUnderpriced(err) || errors.Is(err, ethereum.NotFound) {
+				continue // 本轮交易池不接受，下轮基于更高价格再试
+			}
+			return nil, fmt.Errorf("send replacement: %w", err)
+		}
+		bumps++
+		sent = append(sent, signed)
+		if b.OnBump != nil {
+			b.OnBump(latest, signed)
+		}
+	}
+}
+
+// findMined 在所有已发送的版本里找出真正上链的那一个
+func (b *Bumper) findMined(ctx context.Context, sent []*types.Transaction) (*types.Receipt, error) {
+	for _, tx := range sent {
+		rcpt, err := b.Tracker.client.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			continue
+		}
+		if rcpt.Status != types.ReceiptStatusSuccessful {
+			from, _ := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+			reason := b.Tracker.revertReason(ctx, &trackState{tx: tx, from: from}, rcpt)
+			return rcpt, &RevertError{Receipt: rcpt, Reason: reason}
+		}
+		return rcpt, nil
+	}
+	return nil, ErrReplaced // nonce 被完全不相关的交易占用
+}
+
+func feeCapOf(tx *types.Transaction) *big.Int {
+	if tx.Type() == types.DynamicFeeTxType {
+		return tx.GasFeeCap()
+	}
+	return tx.GasPrice()
+}
@@ -0,0 +1,142 @@
+package txlife
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80")
+	testAddr    = crypto.PubkeyToAddress(testKey.PublicKey)
+	testChainID = big.NewInt(1337)
+)
+
+func signLegacy(t *testing.T, tx *types.Transaction, chainID *big.Int) *types.Transaction {
+	t.Helper()
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func legacyOrig(t *testing.T) *types.Transaction {
+	to := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	return signLegacy(t, types.NewTx(&types.LegacyTx{
+		Nonce:    7,
+		GasPrice: big.NewInt(1_000_000_000),
+		Gas:      21000,
+		To:       &to,
+		Value:    big.NewInt(1),
+	}), testChainID)
+}
+
+func checkSigned(t *testing.T, tx *types.Transaction) {
+	t.Helper()
+	if tx.ChainId().Cmp(testChainID) != 0 {
+		t.Fatalf("chainId = %v, want %v", tx.ChainId(), testChainID)
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(testChainID), tx)
+	if err != nil {
+		t.Fatalf("recover sender: %v", err)
+	}
+	if from != testAddr {
+		t.Fatalf("sender = %s, want %s", from.Hex(), testAddr.Hex())
+	}
+}
+
+func TestBumpedLegacyChainID(t *testing.T) {
+	orig := legacyOrig(t)
+
+	bumped, err := Bumped(orig, 10, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := big.NewInt(1_100_000_000); bumped.GasPrice().Cmp(want) != 0 {
+		t.Fatalf("gasPrice = %v, want %v", bumped.GasPrice(), want)
+	}
+	signed := signLegacy(t, bumped, orig.ChainId())
+	checkSigned(t, signed)
+	if signed.Nonce() != orig.Nonce() {
+		t.Fatalf("nonce = %d, want %d", signed.Nonce(), orig.Nonce())
+	}
+
+	cancel, err := Cancellation(orig, testAddr, 10, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed = signLegacy(t, cancel, orig.ChainId())
+	checkSigned(t, signed)
+	if *signed.To() != testAddr || signed.Value().Sign() != 0 || signed.Gas() != 21000 {
+		t.Fatalf("cancellation = to %s value %v gas %d", signed.To().Hex(), signed.Value(), signed.Gas())
+	}
+}
+
+// fakeChain 只打包通过 Send 发出的交易：原交易一直 pending，直到某个加价版本被发送
+type fakeChain struct {
+	mu    sync.Mutex
+	mined map[common.Hash]bool
+}
+
+func (c *fakeChain) TransactionReceipt(ctx context.Context, h common.Hash) (*types.Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.mined[h] {
+		return nil, ethereum.NotFound
+	}
+	return &types.Receipt{TxHash: h, Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(10)}, nil
+}
+
+func (c *fakeChain) TransactionByHash(ctx context.Context, h common.Hash) (*types.Transaction, bool, error) {
+	return nil, true, nil
+}
+
+func (c *fakeChain) NonceAt(ctx context.Context, a common.Address, n *big.Int) (uint64, error) {
+	return 7, nil
+}
+
+func (c *fakeChain) HeaderByNumber(ctx context.Context, n *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(10)}, nil
+}
+
+func (c *fakeChain) CallContract(ctx context.Context, msg ethereum.CallMsg, n *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func TestBumperLegacySignsForOriginalChain(t *testing.T) {
+	chain := &fakeChain{mined: map[common.Hash]bool{}}
+	var sent []*types.Transaction
+	b := &Bumper{
+		Tracker: NewTracker(chain, Config{PollInterval: 5 * time.Millisecond}),
+		Sign: func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+			return types.SignTx(tx, types.LatestSignerForChainID(chainID), testKey)
+		},
+		Send: func(ctx context.Context, tx *types.Transaction) error {
+			chain.mu.Lock()
+			defer chain.mu.Unlock()
+			sent = append(sent, tx)
+			chain.mined[tx.Hash()] = true
+			return nil
+		},
+		Every: 30 * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	rcpt, err := b.Wait(ctx, legacyOrig(t), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || rcpt.TxHash != sent[0].Hash() {
+		t.Fatalf("sent %d replacements, receipt %s", len(sent), rcpt.TxHash.Hex())
+	}
+	checkSigned(t, sent[0])
+}
@@ -0,0 +1,66 @@
+package txlife
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// revertReason 在交易所在区块的父块状态上用 eth_call 重放一次，从返回的 revert data 里解码失败原因。
+// 回执本身不带原因，只能靠重放；用父块状态是为了尽量还原执行前的环境（同块里排在前面的交易除外）。
+// 重放拿不到数据（例如节点裁剪了状态）时返回空字符串。
+func (t *Tracker) revertReason(ctx context.Context, st *trackState, rcpt *types.Receipt) string {
+	msg := ethereum.CallMsg{
+		From:  st.from,
+		To:    st.tx.To(),
+		Gas:   st.tx.Gas(),
+		Value: st.tx.Value(),
+		Data:  st.tx.Data(),
+	}
+	parent := new(big.Int).Sub(rcpt.BlockNumber, common.Big1)
+	_, err := t.client.CallContract(ctx, msg, parent)
+	if err == nil {
+		return ""
+	}
+	var de rpc.DataError
+	if !errors.As(err, &de) {
+		return err.Error()
+	}
+	hexData, _ := de.ErrorData().(string)
+	data, decErr := hexutil.Decode(hexData)
+	if decErr != nil || len(data) < 4 {
+		return de.Error()
+	}
+	return DecodeRevert(data, t.cfg.ErrorABI)
+}
+
+// DecodeRevert 解码 revert data：优先识别 Error(string) / Panic(uint256)，
+// 其次按 errABI（可为 nil）里的自定义 error 解码，都不认识则返回原始十六进制
+func DecodeRevert(data []byte, errABI *abi.ABI) string {
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return reason
+	}
+	if errABI != nil && len(data) >= 4 {
+		var id [4]byte
+		copy(id[:], data[:4])
+		if e, err := errABI.ErrorByID(id); err == nil {
+			if vals, err := e.Inputs.Unpack(data[4:]); err == nil {
+				parts := make([]string, len(vals))
+				for i, v := range vals {
+					parts[i] = fmt.Sprintf("%v", v)
+				}
+				return fmt.Sprintf("%s(%s)", e.Name, strings.Join(parts, ", "))
+			}
+		}
+	}
+	return hexutil.Encode(data)
+}
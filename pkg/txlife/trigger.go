@@ -0,0 +1,70 @@
+package txlife
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Trigger 产生“该检查一次了”的信号；返回的 channel 被关闭表示触发源失效，Tracker 会退回轮询
+type Trigger func(ctx context.Context) (<-chan struct{}, error)
+
+// PollEvery 每隔 d 触发一次
+func PollEvery(d time.Duration) Trigger {
+	return func(ctx context.Context) (<-chan struct{}, error) {
+		ch := make(chan struct{}, 1)
+		go func() {
+			ticker := time.NewTicker(d)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					select {
+					case ch <- struct{}{}:
+					default: // 上一次还没消费，合并
+					}
+				}
+			}
+		}()
+		return ch, nil
+	}
+}
+
+// HeadSubscriber 是 OnNewHead 需要的能力（需要 websocket/IPC 连接）
+type HeadSubscriber interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// OnNewHead 每收到一个新区块头触发一次；订阅断开时关闭 channel
+func OnNewHead(s HeadSubscriber) Trigger {
+	return func(ctx context.Context) (<-chan struct{}, error) {
+		heads := make(chan *types.Header, 16)
+		sub, err := s.SubscribeNewHead(ctx, heads)
+		if err != nil {
+			return nil, err
+		}
+		ch := make(chan struct{}, 1)
+		go func() {
+			defer close(ch)
+			defer sub.Unsubscribe()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-sub.Err():
+					return
+				case <-heads:
+					select {
+					case ch <- struct{}{}:
+					default:
+					}
+				}
+			}
+		}()
+		return ch, nil
+	}
+}
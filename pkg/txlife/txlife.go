@@ -0,0 +1,275 @@
+// Package txlife 跟踪一笔已广播交易的完整生命周期：
+// pending → mined → N 确认 → finalized，并识别被丢弃（dropped）、被替换（replaced）与执行失败（reverted）。
+// 检查时机由 Trigger 决定：可以定时轮询，也可以每来一个新区块头检查一次。
+package txlife
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// State 是交易在跟踪过程中的状态
+type State int
+
+const (
+	StatePending   State = iota // 已在交易池中，尚未打包
+	StateMined                  // 已打包且执行成功
+	StateConfirmed              // 达到配置的确认数（终态，除非要求 finalized）
+	StateFinalized              // 所在区块已 finalized（终态）
+	StateReverted               // 已打包但执行失败（终态）
+	StateDropped                // 长时间不在交易池且 nonce 未被消耗（终态）
+	StateReplaced               // 同 nonce 已被另一笔交易上链（终态）
+)
+
+func (s State) String() string {
+	switch s {
+	case StatePending:
+		return "pending"
+	case StateMined:
+		return "mined"
+	case StateConfirmed:
+		return "confirmed"
+	case StateFinalized:
+		return "finalized"
+	case StateReverted:
+		return "reverted"
+	case StateDropped:
+		return "dropped"
+	case StateReplaced:
+		return "replaced"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// Terminal 表示该状态之后不会再变化
+func (s State) Terminal() bool {
+	return s >= StateFinalized || s == StateConfirmed
+}
+
+var (
+	ErrDropped  = errors.New("transaction dropped from mempool")
+	ErrReplaced = errors.New("transaction replaced: nonce consumed by another transaction")
+)
+
+// RevertError 表示交易已上链但执行失败；Reason 为解码出的失败原因（可能为空）
+type RevertError struct {
+	Receipt *types.Receipt
+	Reason  string
+}
+
+func (e *RevertError) Error() string {
+	if e.Reason == "" {
+		return "execution reverted"
+	}
+	return "execution reverted: " + e.Reason
+}
+
+// Update 是一次状态变化通知
+type Update struct {
+	Tx            common.Hash
+	State         State
+	Receipt       *types.Receipt // mined 及之后才有
+	Confirmations uint64         // 包含交易所在区块本身
+	RevertReason  string         // 仅 StateReverted
+}
+
+// Client 是跟踪所需的节点能力；*ethclient.Client 满足
+type Client interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// Config 控制跟踪行为；零值即可用
+type Config struct {
+	Confirmations uint64        // 需要的确认数，默认 1（打包即确认）
+	WaitFinalized bool          // 为 true 时一直等到所在区块 finalized
+	Trigger       Trigger       // 检查时机，默认 PollEvery(PollInterval)
+	PollInterval  time.Duration // 默认 2s；Trigger 失效时也按它退回轮询
+	DropTimeout   time.Duration // 连续多久查不到（且 nonce 未消耗）判定为 dropped，默认 2min
+	ErrorABI      *abi.ABI      // 可选：用于解码自定义 error 的合约 ABI
+}
+
+// Tracker 按 Config 跟踪交易
+type Tracker struct {
+	client Client
+	cfg    Config
+}
+
+func NewTracker(c Client, cfg Config) *Tracker {
+	if cfg.Confirmations == 0 {
+		cfg.Confirmations = 1
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 2 * time.Second
+	}
+	if cfg.DropTimeout <= 0 {
+		cfg.DropTimeout = 2 * time.Minute
+	}
+	if cfg.Trigger == nil {
+		cfg.Trigger = PollEvery(cfg.PollInterval)
+	}
+	return &Tracker{client: c, cfg: cfg}
+}
+
+// Wait 用默认配置等待 tx 打包，返回回执。
+// 执行失败返回 *RevertError（回执同时返回），被丢弃/替换分别返回 ErrDropped / ErrReplaced。
+func Wait(ctx context.Context, c Client, tx *types.Transaction) (*types.Receipt, error) {
+	return NewTracker(c, Config{}).Wait(ctx, tx, nil)
+}
+
+// Wait 跟踪到终态并把终态转换为 (receipt, error)；onUpdate 可为 nil
+func (t *Tracker) Wait(ctx context.Context, tx *types.Transaction, onUpdate func(Update)) (*types.Receipt, error) {
+	u, err := t.Track(ctx, tx, onUpdate)
+	if err != nil {
+		return nil, err
+	}
+	switch u.State {
+	case StateReverted:
+		return u.Receipt, &RevertError{Receipt: u.Receipt, Reason: u.RevertReason}
+	case StateDropped:
+		return nil, ErrDropped
+	case StateReplaced:
+		return nil, ErrReplaced
+	}
+	return u.Receipt, nil
+}
+
+// Track 跟踪 tx 直到终态或 ctx 结束，每次状态变化都会回调 onUpdate（可为 nil），返回终态。
+// 查询节点时的临时错误不会中断跟踪，下一次触发时重试；ctx 结束时返回的错误里带上最后一次查询错误。
+func (t *Tracker) Track(ctx context.Context, tx *types.Transaction, onUpdate func(Update)) (Update, error) {
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return Update{}, fmt.Errorf("recover sender: %w", err)
+	}
+	ticks, err := t.cfg.Trigger(ctx)
+	if err != nil {
+		ticks, _ = PollEvery(t.cfg.PollInterval)(ctx) // 触发源不可用，退回轮询
+	}
+
+	st := &trackState{tx: tx, from: from, lastSeen: time.Now(), last: Update{Tx: tx.Hash(), State: -1}}
+	var lastErr error
+	for {
+		u, err := t.check(ctx, st)
+		if err != nil {
+			lastErr = err
+		} else if u.State != st.last.State || u.Confirmations != st.last.Confirmations {
+			st.last = u
+			if onUpdate != nil {
+				onUpdate(u)
+			}
+			if u.State.Terminal() {
+				return u, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if lastErr != nil {
+				return st.last, fmt.Errorf("%w (last error: %v)", ctx.Err(), lastErr)
+			}
+			return st.last, ctx.Err()
+		case _, ok := <-ticks:
+			if !ok {
+				ticks, _ = PollEvery(t.cfg.PollInterval)(ctx)
+			}
+		}
+	}
+}
+
+type trackState struct {
+	tx       *types.Transaction
+	from     common.Address
+	lastSeen time.Time // 最后一次在交易池/链上看到它的时间
+	last     Update
+}
+
+// check 查询一次当前状态
+func (t *Tracker) check(ctx context.Context, st *trackState) (Update, error) {
+	hash := st.tx.Hash()
+	rcpt, err := t.client.TransactionReceipt(ctx, hash)
+	if err == nil && rcpt != nil {
+		st.lastSeen = time.Now()
+		return t.checkMined(ctx, st, rcpt)
+	}
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
+		return Update{}, fmt.Errorf("receipt: %w", err)
+	}
+
+	// 没有回执：先看 nonce 是否已被消耗
+	nonce, err := t.client.NonceAt(ctx, st.from, nil)
+	if err != nil {
+		return Update{}, fmt.Errorf("nonce: %w", err)
+	}
+	if nonce > st.tx.Nonce() {
+		// 可能刚好在两次查询之间被打包，再确认一次回执
+		rcpt, err := t.client.TransactionReceipt(ctx, hash)
+		if err == nil && rcpt != nil {
+			return t.checkMined(ctx, st, rcpt)
+		}
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return Update{}, fmt.Errorf("receipt: %w", err)
+		}
+		return Update{Tx: hash, State: StateReplaced}, nil
+	}
+
+	// nonce 未消耗：看是否还在交易池里
+	_, _, err = t.client.TransactionByHash(ctx, hash)
+	switch {
+	case err == nil:
+		st.lastSeen = time.Now()
+	case errors.Is(err, ethereum.NotFound):
+		if time.Since(st.lastSeen) >= t.cfg.DropTimeout {
+			return Update{Tx: hash, State: StateDropped}, nil
+		}
+	default:
+		return Update{}, fmt.Errorf("tx by hash: %w", err)
+	}
+	return Update{Tx: hash, State: StatePending}, nil
+}
+
+// checkMined 根据回执计算确认数 / finalized，并解码失败原因
+func (t *Tracker) checkMined(ctx context.Context, st *trackState, rcpt *types.Receipt) (Update, error) {
+	u := Update{Tx: rcpt.TxHash, State: StateMined, Receipt: rcpt}
+	if rcpt.Status != types.ReceiptStatusSuccessful {
+		u.State = StateReverted
+		u.RevertReason = t.revertReason(ctx, st, rcpt)
+		return u, nil
+	}
+
+	head, err := t.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return Update{}, fmt.Errorf("head: %w", err)
+	}
+	if head.Number.Cmp(rcpt.BlockNumber) >= 0 {
+		u.Confirmations = new(big.Int).Sub(head.Number, rcpt.BlockNumber).Uint64() + 1
+	}
+	if u.Confirmations >= t.cfg.Confirmations {
+		u.State = StateConfirmed
+	}
+	if !t.cfg.WaitFinalized || u.State != StateConfirmed {
+		return u, nil
+	}
+
+	fin, err := t.client.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)))
+	if err != nil {
+		return Update{}, fmt.Errorf("finalized head: %w", err)
+	}
+	if fin.Number.Cmp(rcpt.BlockNumber) >= 0 {
+		u.State = StateFinalized
+	} else {
+		u.State = StateMined // 确认数够了但还没 finalized，继续等
+	}
+	return u, nil
+}
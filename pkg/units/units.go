@@ -0,0 +1,157 @@
+// Package units 在整数（wei / 代币最小单位）与十进制字符串之间精确换算。
+// 全程只用 big.Int 的整数运算，不经过 float64 / big.Float；解析时多余的小数位直接报错而不是静默截断。
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// 常用的小数位数；ERC-20 代币用合约 decimals() 的返回值
+const (
+	Wei   = 0
+	Gwei  = 9
+	Ether = 18
+)
+
+var (
+	// ErrSyntax：不是合法的十进制数（只接受可选符号、数字和至多一个小数点，不接受指数 / 千分位）
+	ErrSyntax = errors.New("units: invalid decimal amount")
+	// ErrPrecision：小数位多于 decimals，无法精确表示
+	ErrPrecision = errors.New("units: too many decimal places")
+	// ErrNegative：ParseUnsigned / ParseEther 遇到负数（转账金额、交易 value 不能为负）
+	ErrNegative = errors.New("units: negative amount")
+)
+
+// Parse 把十进制字符串按 decimals 位小数换算成整数，例如 Parse("0.1", 18) = 1e17。
+// 小数部分超出 decimals 的位只允许是 0（"1.50" 按 1 位小数解析为 15），否则返回 ErrPrecision。
+func Parse(s string, decimals int) (*big.Int, error) {
+	if decimals < 0 {
+		return nil, fmt.Errorf("units: negative decimals %d", decimals)
+	}
+	str := strings.TrimSpace(s)
+	neg := false
+	if len(str) > 0 && (str[0] == '-' || str[0] == '+') {
+		neg = str[0] == '-'
+		str = str[1:]
+	}
+	intPart, frac, _ := strings.Cut(str, ".")
+	if intPart == "" && frac == "" || !isDigits(intPart) || !isDigits(frac) {
+		return nil, fmt.Errorf("%w %q", ErrSyntax, s)
+	}
+	if len(frac) > decimals {
+		if strings.TrimRight(frac[decimals:], "0") != "" {
+			return nil, fmt.Errorf("%w: %q has more than %d", ErrPrecision, s, decimals)
+		}
+		frac = frac[:decimals]
+	}
+	digits := intPart + frac + strings.Repeat("0", decimals-len(frac))
+	v, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrSyntax, s)
+	}
+	if neg {
+		v.Neg(v)
+	}
+	return v, nil
+}
+
+// ParseUnsigned 同 Parse，但拒绝负数（"-0" 视为 0），用于解析用户输入的转账金额
+func ParseUnsigned(s string, decimals int) (*big.Int, error) {
+	v, err := Parse(s, decimals)
+	if err != nil {
+		return nil, err
+	}
+	if v.Sign() < 0 {
+		return nil, fmt.Errorf("%w %q", ErrNegative, s)
+	}
+	return v, nil
+}
+
+// Format 把整数按 decimals 位小数精确格式化，去掉小数末尾的 0，例如 Format(1e17, 18) = "0.1"。
+// nil 视为 0。Parse(Format(v, d), d) 总是等于 v。decimals 为负时 panic（Parse 对此返回错误）。
+func Format(v *big.Int, decimals int) string {
+	ip, fp, neg := split(v, decimals)
+	fp = strings.TrimRight(fp, "0")
+	return join(ip, fp, neg)
+}
+
+// FormatFixed 格式化为固定 places 位小数，用于表格等需要对齐的显示。
+// places 小于 decimals 时按四舍五入（远离零）舍入，结果不再精确；places 更大时补 0。
+// decimals 为负时 panic，同 Format。
+func FormatFixed(v *big.Int, decimals, places int) string {
+	checkDecimals(decimals)
+	if places < 0 {
+		places = 0
+	}
+	if v == nil {
+		v = new(big.Int)
+	}
+	if places < decimals {
+		scale := pow10(decimals - places)
+		half := new(big.Int).Rsh(scale, 1)
+		abs := new(big.Int).Abs(v)
+		abs.Add(abs, half).Quo(abs, scale)
+		if v.Sign() < 0 {
+			abs.Neg(abs)
+		}
+		v, decimals = abs, places
+	}
+	ip, fp, neg := split(v, decimals)
+	fp += strings.Repeat("0", places-len(fp))
+	return join(ip, fp, neg)
+}
+
+// ParseEther / FormatEther / FormatGwei 是最常用的几个快捷方式；ParseEther 用于金额，拒绝负数
+func ParseEther(s string) (*big.Int, error) { return ParseUnsigned(s, Ether) }
+func FormatEther(wei *big.Int) string       { return Format(wei, Ether) }
+func FormatGwei(wei *big.Int) string        { return Format(wei, Gwei) }
+
+// split 返回 |v| 的整数部分与补齐到 decimals 位的小数部分
+func split(v *big.Int, decimals int) (ip, fp string, neg bool) {
+	checkDecimals(decimals)
+	if v == nil || v.Sign() == 0 {
+		return "0", strings.Repeat("0", decimals), false
+	}
+	abs := new(big.Int).Abs(v)
+	if decimals == 0 {
+		return abs.String(), "", v.Sign() < 0
+	}
+	q, r := new(big.Int).QuoRem(abs, pow10(decimals), new(big.Int))
+	fp = r.String()
+	fp = strings.Repeat("0", decimals-len(fp)) + fp
+	return q.String(), fp, v.Sign() < 0
+}
+
+// checkDecimals：负的小数位数只可能是调用方的 bug（链上 decimals 是 uint8），不静默当作 0
+func checkDecimals(decimals int) {
+	if decimals < 0 {
+		panic(fmt.Sprintf("units: negative decimals %d", decimals))
+	}
+}
+
+func join(ip, fp string, neg bool) string {
+	s := ip
+	if fp != "" {
+		s += "." + fp
+	}
+	if neg && strings.Trim(s, "0.") != "" {
+		s = "-" + s
+	}
+	return s
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,149 @@
+package units
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func FuzzRoundTrip(f *testing.F) {
+	f.Add([]byte{0x01}, false, uint8(18))
+	f.Add([]byte{0x0d, 0xe0, 0xb6, 0xb3, 0xa7, 0x64, 0x00, 0x00}, true, uint8(18))
+	f.Add([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, false, uint8(0))
+	f.Add([]byte{}, false, uint8(6))
+	f.Fuzz(func(t *testing.T, mag []byte, neg bool, d uint8) {
+		v := new(big.Int).SetBytes(mag)
+		if neg {
+			v.Neg(v)
+		}
+		s := Format(v, int(d))
+		got, err := Parse(s, int(d))
+		if err != nil {
+			t.Fatalf("Parse(Format(%v, %d) = %q): %v", v, d, s, err)
+		}
+		if got.Cmp(v) != 0 {
+			t.Fatalf("Parse(Format(%v, %d) = %q) = %v", v, d, s, got)
+		}
+		// 固定位数（不舍入）同样可逆
+		fixed := FormatFixed(v, int(d), int(d))
+		if got, err := Parse(fixed, int(d)); err != nil || got.Cmp(v) != 0 {
+			t.Fatalf("Parse(FormatFixed(%v, %d) = %q) = %v, %v", v, d, fixed, got, err)
+		}
+	})
+}
+
+func TestParse(t *testing.T) {
+	for _, c := range []struct {
+		in       string
+		decimals int
+		want     string
+		err      error
+	}{
+		{"1", 18, "1000000000000000000", nil},
+		{"0.1", 18, "100000000000000000", nil},
+		{".5", 1, "5", nil},
+		{"5.", 1, "50", nil},
+		{"1.50", 1, "15", nil},
+		{" -2.25 ", 2, "-225", nil},
+		{"+3", 0, "3", nil},
+		{"0.123456", 6, "123456", nil},
+		{"0.1234567", 6, "", ErrPrecision},
+		{"1.5", 0, "", ErrPrecision},
+		{"1e18", 18, "", ErrSyntax},
+		{"1,000", 18, "", ErrSyntax},
+		{"", 18, "", ErrSyntax},
+		{".", 18, "", ErrSyntax},
+		{"-", 18, "", ErrSyntax},
+		{"1.2.3", 18, "", ErrSyntax},
+		{"0x10", 18, "", ErrSyntax},
+		{"--1", 18, "", ErrSyntax},
+	} {
+		got, err := Parse(c.in, c.decimals)
+		if c.err != nil {
+			if !errors.Is(err, c.err) {
+				t.Errorf("Parse(%q, %d) err = %v, want %v", c.in, c.decimals, err, c.err)
+			}
+			continue
+		}
+		if err != nil || got.String() != c.want {
+			t.Errorf("Parse(%q, %d) = %v, %v, want %s", c.in, c.decimals, got, err, c.want)
+		}
+	}
+	if _, err := Parse("1", -1); err == nil {
+		t.Error("Parse with negative decimals accepted")
+	}
+}
+
+func TestParseUnsigned(t *testing.T) {
+	if _, err := ParseEther("-0.1"); !errors.Is(err, ErrNegative) {
+		t.Errorf("ParseEther(-0.1) err = %v, want ErrNegative", err)
+	}
+	if v, err := ParseEther("-0"); err != nil || v.Sign() != 0 {
+		t.Errorf("ParseEther(-0) = %v, %v", v, err)
+	}
+	if v, err := ParseUnsigned("12.5", 6); err != nil || v.Int64() != 12_500_000 {
+		t.Errorf("ParseUnsigned(12.5, 6) = %v, %v", v, err)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	for _, c := range []struct {
+		v        string
+		decimals int
+		want     string
+	}{
+		{"0", 18, "0"},
+		{"1", 18, "0.000000000000000001"},
+		{"1000000000000000000", 18, "1"},
+		{"-1500000000000000000", 18, "-1.5"},
+		{"123", 0, "123"},
+		{"-5", 1, "-0.5"},
+	} {
+		v, _ := new(big.Int).SetString(c.v, 10)
+		if got := Format(v, c.decimals); got != c.want {
+			t.Errorf("Format(%s, %d) = %q, want %q", c.v, c.decimals, got, c.want)
+		}
+	}
+	if got := Format(nil, 2); got != "0" {
+		t.Errorf("Format(nil) = %q", got)
+	}
+}
+
+func TestFormatFixed(t *testing.T) {
+	for _, c := range []struct {
+		v               string
+		decimals, place int
+		want            string
+	}{
+		{"1234567", 6, 2, "1.23"},
+		{"1235000", 6, 2, "1.24"}, // 恰好一半：远离零
+		{"-1235000", 6, 2, "-1.24"},
+		{"-1234999", 6, 2, "-1.23"},
+		{"-4999", 6, 2, "0.00"}, // 舍入到 0 时不带负号
+		{"-5000", 6, 2, "-0.01"},
+		{"-1500000", 6, 0, "-2"},
+		{"15", 1, 3, "1.500"},
+		{"0", 18, 4, "0.0000"},
+	} {
+		v, _ := new(big.Int).SetString(c.v, 10)
+		if got := FormatFixed(v, c.decimals, c.place); got != c.want {
+			t.Errorf("FormatFixed(%s, %d, %d) = %q, want %q", c.v, c.decimals, c.place, got, c.want)
+		}
+	}
+}
+
+func TestNegativeDecimalsPanics(t *testing.T) {
+	for name, fn := range map[string]func(){
+		"Format":      func() { Format(big.NewInt(1), -1) },
+		"FormatFixed": func() { FormatFixed(big.NewInt(1), -1, 2) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s with negative decimals did not panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
@@ -0,0 +1,219 @@
+// Package wallet 用 Web3 Secret Storage（keystore v3 JSON）管理私钥：创建、导入、列出、用口令解锁，
+// 解锁后给出 Signer 交给转账 / 部署流程签名，代码里不再出现明文私钥。
+package wallet
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/term"
+)
+
+var (
+	// ErrNoKeystore 表示没有设置 KEYSTORE_DIR，调用方应退回原来的私钥读取方式
+	ErrNoKeystore = errors.New("wallet: KEYSTORE_DIR not set")
+	// ErrNoAccount 表示 keystore 目录里没有账户
+	ErrNoAccount = errors.New("wallet: no account in keystore")
+)
+
+// Store 是一个 keystore 目录
+type Store struct {
+	dir string
+	ks  *keystore.KeyStore
+}
+
+// Open 打开（不存在则创建）keystore 目录。light 为 true 时用轻量 scrypt 参数，仅用于测试 / 演示，加解密快但更易被暴力破解。
+func Open(dir string, light bool) *Store {
+	n, p := keystore.StandardScryptN, keystore.StandardScryptP
+	if light {
+		n, p = keystore.LightScryptN, keystore.LightScryptP
+	}
+	return &Store{dir: dir, ks: keystore.NewKeyStore(dir, n, p)}
+}
+
+// Dir 返回 keystore 目录
+func (s *Store) Dir() string { return s.dir }
+
+// Create 生成新账户并用 passphrase 加密落盘
+func (s *Store) Create(passphrase string) (accounts.Account, error) {
+	return s.ks.NewAccount(passphrase)
+}
+
+// ImportKey 把已有私钥加密导入
+func (s *Store) ImportKey(priv *ecdsa.PrivateKey, passphrase string) (accounts.Account, error) {
+	return s.ks.ImportECDSA(priv, passphrase)
+}
+
+// ImportHex 导入十六进制私钥（可带 0x 前缀）
+func (s *Store) ImportHex(hexKey, passphrase string) (accounts.Account, error) {
+	priv, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(hexKey), "0x"))
+	if err != nil {
+		return accounts.Account{}, fmt.Errorf("parse private key: %w", err)
+	}
+	return s.ImportKey(priv, passphrase)
+}
+
+// ImportJSON 导入其它钱包导出的 keystore 文件：oldPass 解密，newPass 重新加密
+func (s *Store) ImportJSON(keyJSON []byte, oldPass, newPass string) (accounts.Account, error) {
+	return s.ks.Import(keyJSON, oldPass, newPass)
+}
+
+// Accounts 列出目录中的全部账户
+func (s *Store) Accounts() []accounts.Account {
+	return s.ks.Accounts()
+}
+
+// Find 按地址查找账户；addr 为空且目录里只有一个账户时直接返回它
+func (s *Store) Find(addr string) (accounts.Account, error) {
+	all := s.ks.Accounts()
+	if addr == "" {
+		switch len(all) {
+		case 0:
+			return accounts.Account{}, fmt.Errorf("%w: %s", ErrNoAccount, s.dir)
+		case 1:
+			return all[0], nil
+		default:
+			return accounts.Account{}, fmt.Errorf("keystore %s has %d accounts, choose one with KEYSTORE_ACCOUNT", s.dir, len(all))
+		}
+	}
+	if !common.IsHexAddress(addr) {
+		return accounts.Account{}, fmt.Errorf("invalid account address %q", addr)
+	}
+	return s.ks.Find(accounts.Account{Address: common.HexToAddress(addr)})
+}
+
+// Unlock 用口令解锁账户并返回 Signer；解密后的私钥只留在 keystore 内存中
+func (s *Store) Unlock(acct accounts.Account, passphrase string) (*Signer, error) {
+	if err := s.ks.Unlock(acct, passphrase); err != nil {
+		return nil, fmt.Errorf("unlock %s: %w", acct.Address.Hex(), err)
+	}
+	return &Signer{
+		Address: acct.Address,
+		sign:    func(hash []byte) ([]byte, error) { return s.ks.SignHash(acct, hash) },
+	}, nil
+}
+
+// Signer 对交易 / 哈希签名；背后可以是 keystore 账户，也可以是内存里的私钥
+type Signer struct {
+	Address common.Address
+	sign    func(hash []byte) ([]byte, error)
+}
+
+// FromKey 用明文私钥构造 Signer，兼容仍在用 PRIV_KEY_HEX 的流程
+func FromKey(priv *ecdsa.PrivateKey) *Signer {
+	return &Signer{
+		Address: crypto.PubkeyToAddress(priv.PublicKey),
+		sign:    func(hash []byte) ([]byte, error) { return crypto.Sign(hash, priv) },
+	}
+}
+
+// SignHash 对 32 字节哈希签名，返回 [R || S || V]（V 为 0/1）
+func (s *Signer) SignHash(hash []byte) ([]byte, error) {
+	return s.sign(hash)
+}
+
+// SignTx 用 LatestSignerForChainID 对交易签名
+func (s *Signer) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	signer := types.LatestSignerForChainID(chainID)
+	sig, err := s.sign(signer.Hash(tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(signer, sig)
+}
+
+// TransactOpts 给 abigen 绑定用的交易参数
+func (s *Signer) TransactOpts(chainID *big.Int) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From: s.Address,
+		Signer: func(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if addr != s.Address {
+				return nil, bind.ErrNotAuthorized
+			}
+			return s.SignTx(tx, chainID)
+		},
+	}
+}
+
+// SignerFromEnv 按环境变量解锁 keystore 账户：
+//
+//	KEYSTORE_DIR       keystore 目录；未设置时返回 ErrNoKeystore
+//	KEYSTORE_ACCOUNT   账户地址；目录里只有一个账户时可省略
+//	KEYSTORE_PASS      口令；也可用 KEYSTORE_PASS_FILE 指定口令文件，都没有时从终端读取
+func SignerFromEnv() (*Signer, error) {
+	dir := os.Getenv("KEYSTORE_DIR")
+	if dir == "" {
+		return nil, ErrNoKeystore
+	}
+	s := Open(dir, false)
+	acct, err := s.Find(os.Getenv("KEYSTORE_ACCOUNT"))
+	if err != nil {
+		return nil, err
+	}
+	pass, err := Passphrase(fmt.Sprintf("Passphrase for %s: ", acct.Address.Hex()))
+	if err != nil {
+		return nil, err
+	}
+	return s.Unlock(acct, pass)
+}
+
+// LoadSigner 设置了 KEYSTORE_DIR 时解锁 keystore 账户，否则退回用 fallbackHex 私钥，兼容旧流程；
+// 两者都没有时返回 ErrNoKeystore
+func LoadSigner(fallbackHex string) (*Signer, error) {
+	s, err := SignerFromEnv()
+	if !errors.Is(err, ErrNoKeystore) || fallbackHex == "" {
+		return s, err
+	}
+	priv, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(fallbackHex), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+	return FromKey(priv), nil
+}
+
+// Passphrase 依次从 KEYSTORE_PASS、KEYSTORE_PASS_FILE、标准输入读取口令。
+func Passphrase(prompt string) (string, error) {
+	if p := os.Getenv("KEYSTORE_PASS"); p != "" {
+		return p, nil
+	}
+	if f := os.Getenv("KEYSTORE_PASS_FILE"); f != "" {
+		raw, err := os.ReadFile(f)
+		if err != nil {
+			return "", fmt.Errorf("read passphrase file: %w", err)
+		}
+		return strings.TrimRight(string(raw), "\r\n"), nil
+	}
+	return ReadPassword(prompt)
+}
+
+// stdin 共用一个 reader，管道输入时连续读两行（输两遍口令）不会丢数据
+var stdin = bufio.NewReader(os.Stdin)
+
+// ReadPassword 从标准输入读取一行口令：终端下关闭回显，管道 / 重定向输入时按行读取
+func ReadPassword(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		pass, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr) // 回车没有回显，补一个换行
+		if err != nil {
+			return "", fmt.Errorf("read passphrase: %w", err)
+		}
+		return string(pass), nil
+	}
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("read passphrase: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,91 @@
+package wallet
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// hardhat / anvil 的 0 号测试账户
+const (
+	testKey  = "0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+	testAddr = "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+)
+
+// importTestKey 用轻量 scrypt 参数把测试私钥加密进临时 keystore，并设置 LoadSigner 需要的环境变量
+func importTestKey(t *testing.T, pass string) {
+	t.Helper()
+	dir := t.TempDir()
+	acct, err := Open(dir, true).ImportHex(testKey, pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acct.Address != common.HexToAddress(testAddr) {
+		t.Fatalf("imported %s, want %s", acct.Address.Hex(), testAddr)
+	}
+	t.Setenv("KEYSTORE_DIR", dir)
+	t.Setenv("KEYSTORE_ACCOUNT", "")
+	t.Setenv("KEYSTORE_PASS_FILE", "")
+}
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	importTestKey(t, "correct horse")
+	t.Setenv("KEYSTORE_PASS", "correct horse")
+
+	s, err := LoadSigner("") // 设置了 KEYSTORE_DIR 时不使用明文私钥
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Address != common.HexToAddress(testAddr) {
+		t.Fatalf("signer = %s, want %s", s.Address.Hex(), testAddr)
+	}
+
+	// 解锁后的签名与直接用私钥签名一致
+	hash := crypto.Keccak256([]byte("hello"))
+	sig, err := s.SignHash(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := crypto.SigToPub(hash, sig)
+	if err != nil || crypto.PubkeyToAddress(*pub) != s.Address {
+		t.Fatalf("signature recovers to %v, %v", pub, err)
+	}
+
+	chainID := big.NewInt(11155111)
+	tx, err := s.SignTx(types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 1}), chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from, err := types.Sender(types.LatestSignerForChainID(chainID), tx); err != nil || from != s.Address {
+		t.Errorf("tx sender = %s, %v", from.Hex(), err)
+	}
+}
+
+func TestWrongPassphrase(t *testing.T) {
+	importTestKey(t, "correct horse")
+	t.Setenv("KEYSTORE_PASS", "battery staple")
+
+	// 即使给了明文私钥，keystore 解锁失败也不能退回私钥
+	if _, err := LoadSigner(testKey); !errors.Is(err, keystore.ErrDecrypt) {
+		t.Fatalf("err = %v, want %v", err, keystore.ErrDecrypt)
+	}
+}
+
+func TestLoadSignerFallback(t *testing.T) {
+	t.Setenv("KEYSTORE_DIR", "")
+	if _, err := LoadSigner(""); !errors.Is(err, ErrNoKeystore) {
+		t.Errorf("no keystore, no key: err = %v, want ErrNoKeystore", err)
+	}
+	s, err := LoadSigner(testKey)
+	if err != nil || s.Address != common.HexToAddress(testAddr) {
+		t.Errorf("fallback key: %v, %v", s, err)
+	}
+	if _, err := LoadSigner("not hex"); err == nil {
+		t.Error("invalid fallback key accepted")
+	}
+}